@@ -0,0 +1,240 @@
+package orderedmap
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValueConflictPolicy decides what BiOrderedMap.Set does when the value is already held by another key.
+type ValueConflictPolicy int
+
+const (
+	// ValueConflictError makes Set fail. (default)
+	ValueConflictError ValueConflictPolicy = iota
+	// ValueConflictReplace deletes the other key.
+	ValueConflictReplace
+)
+
+// WithValueConflictPolicy sets the policy for BiOrderedMap.
+func WithValueConflictPolicy(p ValueConflictPolicy) Option {
+	return func(o *options) {
+		o.valueConflict = p
+	}
+}
+
+// BiOrderedMap is an ordered map whose values are unique and can be looked up too.
+type BiOrderedMap[K, V comparable] struct {
+	m *OrderedMap[K, V]
+
+	byValue map[V]K
+
+	conflict ValueConflictPolicy
+}
+
+func NewBi[K, V comparable](opts ...Option) *BiOrderedMap[K, V] {
+	o := newOptions(opts)
+
+	return &BiOrderedMap[K, V]{
+		m:        New[K, V](opts...),
+		byValue:  make(map[V]K, o.capacity),
+		conflict: o.valueConflict,
+	}
+}
+
+func (m *BiOrderedMap[K, V]) PreserveOrder(b bool) {
+	m.m.PreserveOrder(b)
+}
+
+func (m *BiOrderedMap[K, V]) Set(key K, value V) error {
+	if m == nil {
+		panic("assignment to entry in nil map")
+	}
+
+	if other, found := m.byValue[value]; found {
+		if m.m.mapKey(other) == m.m.mapKey(key) {
+			m.m.Set(key, value)
+			return nil
+		}
+
+		if m.conflict == ValueConflictError {
+			return fmt.Errorf("value %v is already held by key %v", value, other)
+		}
+		m.m.Delete(other)
+	}
+
+	if old, found := m.m.Get(key); found {
+		delete(m.byValue, old)
+	}
+
+	m.m.Set(key, value)
+	m.byValue[value] = m.m.m[m.m.mapKey(key)].key // as first inserted
+	return nil
+}
+
+func (m *BiOrderedMap[K, V]) Delete(key K) {
+	if m == nil {
+		return
+	}
+
+	if value, found := m.m.Get(key); found {
+		delete(m.byValue, value)
+		m.m.Delete(key)
+	}
+}
+
+func (m *BiOrderedMap[K, V]) DeleteByValue(value V) {
+	if m == nil {
+		return
+	}
+
+	if key, found := m.byValue[value]; found {
+		delete(m.byValue, value)
+		m.m.Delete(key)
+	}
+}
+
+func (m *BiOrderedMap[K, V]) Get(key K) (V, bool) {
+	if m == nil {
+		var gnil V
+		return gnil, false
+	}
+	return m.m.Get(key)
+}
+
+func (m *BiOrderedMap[K, V]) GetDefault(key K, defvalue V) V {
+	if m == nil {
+		return defvalue
+	}
+	return m.m.GetDefault(key, defvalue)
+}
+
+func (m *BiOrderedMap[K, V]) GetByValue(value V) (K, bool) {
+	if m == nil {
+		var gnil K
+		return gnil, false
+	}
+
+	key, found := m.byValue[value]
+	return key, found
+}
+
+func (m *BiOrderedMap[K, V]) Contains(key K) bool {
+	return m != nil && m.m.Contains(key)
+}
+
+func (m *BiOrderedMap[K, V]) ContainsValue(value V) bool {
+	if m == nil {
+		return false
+	}
+
+	_, found := m.byValue[value]
+	return found
+}
+
+func (m *BiOrderedMap[K, V]) Len() int {
+	if m == nil {
+		return 0
+	}
+	return m.m.Len()
+}
+
+// Keys returns a copy of the keys in order.
+func (m *BiOrderedMap[K, V]) Keys() []K {
+	if m == nil {
+		return nil
+	}
+	return m.m.Keys()
+}
+
+// Values returns the values in order.
+func (m *BiOrderedMap[K, V]) Values() []V {
+	if m == nil {
+		return nil
+	}
+	return m.m.Values()
+}
+
+// IndexOf returns the position of key, or -1 if key is not found.
+func (m *BiOrderedMap[K, V]) IndexOf(key K) int {
+	if m == nil {
+		return -1
+	}
+	return m.m.IndexOf(key)
+}
+
+// At returns the key and the value at the position i.
+func (m *BiOrderedMap[K, V]) At(i int) (K, V, bool) {
+	if m == nil {
+		var gnilk K
+		var gnilv V
+		return gnilk, gnilv, false
+	}
+	return m.m.At(i)
+}
+
+// OrderedMap returns a copy as an OrderedMap.
+func (m *BiOrderedMap[K, V]) OrderedMap() *OrderedMap[K, V] {
+	if m == nil {
+		return nil
+	}
+	return Filter(m.m, func(K, V) bool { return true })
+}
+
+func (m *BiOrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	return m.m.MarshalJSON()
+}
+
+func (m *BiOrderedMap[K, V]) UnmarshalJSON(b []byte) error {
+	decoded := derive[K, V](m.inner())
+	if err := decoded.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	return m.reset(decoded)
+}
+
+func (m *BiOrderedMap[K, V]) MarshalYAML() (any, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return m.m.MarshalYAML()
+}
+
+func (m *BiOrderedMap[K, V]) UnmarshalYAML(value *yaml.Node) error {
+	decoded := derive[K, V](m.inner())
+	if err := decoded.UnmarshalYAML(value); err != nil {
+		return err
+	}
+	return m.reset(decoded)
+}
+
+func (m BiOrderedMap[K, V]) Format(s fmt.State, verb rune) {
+	m.inner().Format(s, verb)
+}
+
+func (m *BiOrderedMap[K, V]) inner() *OrderedMap[K, V] {
+	if m.m == nil {
+		return New[K, V]()
+	}
+	return m.m
+}
+
+// reset replaces the content with decoded, checking the uniqueness of values.
+func (m *BiOrderedMap[K, V]) reset(decoded *OrderedMap[K, V]) error {
+	bi := &BiOrderedMap[K, V]{
+		m:        derive[K, V](decoded),
+		byValue:  make(map[V]K, decoded.Len()),
+		conflict: m.conflict,
+	}
+	for _, e := range decoded.Entries() {
+		if err := bi.Set(e.Key, e.Value); err != nil {
+			return err
+		}
+	}
+
+	*m = *bi
+	return nil
+}
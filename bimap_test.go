@@ -0,0 +1,118 @@
+package orderedmap_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestBi(t *testing.T) {
+	m := orderedmap.NewBi[string, int]()
+	gotwant.TestError(t, m.Set("red", 1), nil)
+	gotwant.TestError(t, m.Set("green", 2), nil)
+	gotwant.TestError(t, m.Set("blue", 3), nil)
+
+	k, found := m.GetByValue(2)
+	gotwant.Test(t, found, true)
+	gotwant.Test(t, k, "green")
+	gotwant.Test(t, m.GetDefault("blue", 0), 3)
+
+	// update
+	gotwant.TestError(t, m.Set("green", 20), nil)
+	gotwant.Test(t, m.ContainsValue(2), false)
+	k, _ = m.GetByValue(20)
+	gotwant.Test(t, k, "green")
+	gotwant.TestError(t, m.Set("green", 20), nil)
+
+	// conflict
+	gotwant.TestError(t, m.Set("yellow", 1), "value 1 is already held by key red")
+	gotwant.Test(t, m.Contains("yellow"), false)
+	gotwant.TestError(t, m.Set("blue", 1), "value 1 is already held by key red")
+	gotwant.Test(t, m.GetDefault("blue", 0), 3)
+
+	gotwant.Test(t, m.Keys(), []string{"red", "green", "blue"})
+	gotwant.Test(t, m.Values(), []int{1, 20, 3})
+
+	m.Delete("red")
+	gotwant.Test(t, m.ContainsValue(1), false)
+	m.DeleteByValue(3)
+	gotwant.Test(t, m.Contains("blue"), false)
+	gotwant.Test(t, m.Keys(), []string{"green"})
+	gotwant.Test(t, m.Len(), 1)
+}
+
+func TestBiReplace(t *testing.T) {
+	m := orderedmap.NewBi[string, int](orderedmap.WithValueConflictPolicy(orderedmap.ValueConflictReplace))
+	m.Set("red", 1)
+	m.Set("green", 2)
+	m.Set("blue", 3)
+
+	gotwant.TestError(t, m.Set("yellow", 1), nil)
+	gotwant.Test(t, m.Keys(), []string{"green", "blue", "yellow"})
+	k, _ := m.GetByValue(1)
+	gotwant.Test(t, k, "yellow")
+
+	gotwant.TestError(t, m.Set("blue", 2), nil)
+	gotwant.Test(t, m.Keys(), []string{"blue", "yellow"})
+	gotwant.Test(t, m.ContainsValue(3), false)
+	k, _ = m.GetByValue(2)
+	gotwant.Test(t, k, "blue")
+}
+
+func TestBiNormalizer(t *testing.T) {
+	m := orderedmap.NewBi[string, int](orderedmap.WithKeyNormalizer(strings.ToLower))
+	m.Set("Red", 1)
+	gotwant.TestError(t, m.Set("RED", 1), nil)
+	gotwant.TestError(t, m.Set("red", 10), nil)
+	k, _ := m.GetByValue(10)
+	gotwant.Test(t, k, "Red")
+	gotwant.Test(t, m.Len(), 1)
+}
+
+func TestBiMarshal(t *testing.T) {
+	m := orderedmap.NewBi[string, int]()
+	om := orderedmap.New[string, int]()
+	for i, k := range []string{"z", "a", "m"} {
+		m.Set(k, i)
+		om.Set(k, i)
+	}
+
+	b, err := json.Marshal(m)
+	gotwant.TestError(t, err, nil)
+	ob, err := json.Marshal(om)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b), string(ob))
+
+	y, err := yaml.Marshal(m)
+	gotwant.TestError(t, err, nil)
+	oy, err := yaml.Marshal(om)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(y), string(oy))
+
+	gotwant.Test(t, fmt.Sprint(m), fmt.Sprint(om))
+
+	var decoded *orderedmap.BiOrderedMap[string, int]
+	err = json.Unmarshal([]byte(`{"b":1,"a":2}`), &decoded)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, decoded.Keys(), []string{"b", "a"})
+	k, _ := decoded.GetByValue(2)
+	gotwant.Test(t, k, "a")
+
+	err = json.Unmarshal([]byte(`{"b":1,"a":1}`), decoded)
+	gotwant.TestError(t, err, "value 1 is already held by key b")
+	gotwant.Test(t, decoded.Keys(), []string{"b", "a"})
+
+	err = yaml.Unmarshal([]byte("x: 5\ny: 6\n"), decoded)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, decoded.Keys(), []string{"x", "y"})
+	k, _ = decoded.GetByValue(6)
+	gotwant.Test(t, k, "y")
+
+	gotwant.Test(t, decoded.OrderedMap().Keys(), []string{"x", "y"})
+}
@@ -0,0 +1,79 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+// binaryVersion is the first byte of the binary form.
+//
+//	1: gob-encoded []K followed by gob-encoded []V, in order
+const binaryVersion = 1
+
+// MarshalBinary encodes the entries of m in order.
+// The keys and the values are encoded with encoding/gob,
+// so the concrete types held in interface values must be registered by gob.Register.
+func (m *OrderedMap[K, V]) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte(binaryVersion)
+
+	keys := m.Keys()
+	values := m.Values()
+	if keys == nil {
+		keys = []K{}
+		values = []V{}
+	}
+
+	enc := gob.NewEncoder(buf)
+	if err := enc.Encode(keys); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes b made by MarshalBinary into m.
+func (m *OrderedMap[K, V]) UnmarshalBinary(b []byte) error {
+	if len(b) == 0 {
+		return errors.New("orderedmap: empty binary")
+	}
+	if b[0] != binaryVersion {
+		return fmt.Errorf("orderedmap: unsupported binary version %d", b[0])
+	}
+
+	var keys []K
+	var values []V
+	dec := gob.NewDecoder(bytes.NewReader(b[1:]))
+	if err := dec.Decode(&keys); err != nil {
+		return err
+	}
+	if err := dec.Decode(&values); err != nil {
+		return err
+	}
+	if len(keys) != len(values) {
+		return fmt.Errorf("orderedmap: %d keys for %d values", len(keys), len(values))
+	}
+
+	defer m.beginDecode()()
+
+	for i := range keys {
+		if err := m.setDecoded(keys[i], values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GobEncode is the same as MarshalBinary.
+func (m *OrderedMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode is the same as UnmarshalBinary.
+func (m *OrderedMap[K, V]) GobDecode(b []byte) error {
+	return m.UnmarshalBinary(b)
+}
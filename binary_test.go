@@ -0,0 +1,61 @@
+package orderedmap_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestBinary(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	m.Set("z", 1)
+	m.Set("a", 2)
+	m.Set("m", 3)
+	m.Delete("a")
+
+	b, err := m.MarshalBinary()
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, b[0], byte(1))
+
+	m2 := orderedmap.New[string, int]()
+	m2.Set("x", 100)
+	err = m2.UnmarshalBinary(b)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m2.Keys(), []string{"z", "m"})
+	gotwant.Test(t, m2.Values(), []int{1, 3})
+
+	b[0] = 99
+	gotwant.TestError(t, m2.UnmarshalBinary(b), "unsupported binary version 99")
+	gotwant.TestError(t, m2.UnmarshalBinary(nil), "empty")
+}
+
+func TestGob(t *testing.T) {
+	type cache struct {
+		Name  string
+		Items *orderedmap.OrderedMap[string, *orderedmap.OrderedMap[int, any]]
+	}
+
+	sub := orderedmap.New[int, any]()
+	sub.Set(3, "three")
+	sub.Set(1, 1.5)
+
+	items := orderedmap.New[string, *orderedmap.OrderedMap[int, any]]()
+	items.Set("b", sub)
+	items.Set("a", orderedmap.New[int, any]())
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(cache{Name: "c", Items: items})
+	gotwant.TestError(t, err, nil)
+
+	var got cache
+	err = gob.NewDecoder(&buf).Decode(&got)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, got.Name, "c")
+	gotwant.Test(t, got.Items.Keys(), []string{"b", "a"})
+	gotwant.Test(t, got.Items.GetDefault("b", nil).Keys(), []int{3, 1})
+	gotwant.Test(t, got.Items.GetDefault("b", nil).GetDefault(3, nil), "three")
+	gotwant.Test(t, got.Items.GetDefault("a", nil).Len(), 0)
+}
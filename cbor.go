@@ -0,0 +1,387 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// CBOR major types
+const (
+	cborUint   = 0 << 5
+	cborNegint = 1 << 5
+	cborBytes  = 2 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+	cborSimple = 7 << 5
+)
+
+// MarshalCBOR encodes m as a CBOR map, with the entries in order.
+func (m *OrderedMap[K, V]) MarshalCBOR() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if m == nil {
+		buf.WriteByte(cborSimple | 22) // null
+		return buf.Bytes(), nil
+	}
+
+	if err := encodeCBOR(buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalCBOR decodes the CBOR map b into m.
+//
+// Nested maps are decoded into *OrderedMap[string, any], arrays into []any,
+// integers into int64 (uint64 if too large), then converted to V through encoding/json if V is not any.
+func (m *OrderedMap[K, V]) UnmarshalCBOR(b []byte) error {
+	d := &cborDecoder{src: b}
+
+	defer m.beginDecode()()
+
+	if len(b) > 0 && b[0] == cborSimple|22 {
+		d.pos++
+	} else if err := d.readMap(m.setPlain); err != nil {
+		return err
+	}
+
+	if d.pos != len(b) {
+		return errors.New("cbor: extra data")
+	}
+	return nil
+}
+
+func encodeCBOR(buf *bytes.Buffer, v any) error {
+	p, err := plainValue(v)
+	if err != nil {
+		return err
+	}
+
+	switch x := p.(type) {
+	case nil:
+		buf.WriteByte(cborSimple | 22)
+	case bool:
+		if x {
+			buf.WriteByte(cborSimple | 21)
+		} else {
+			buf.WriteByte(cborSimple | 20)
+		}
+	case int64:
+		if x >= 0 {
+			writeCBORHead(buf, cborUint, uint64(x))
+		} else {
+			writeCBORHead(buf, cborNegint, uint64(-1-x))
+		}
+	case uint64:
+		writeCBORHead(buf, cborUint, x)
+	case float64:
+		buf.WriteByte(cborSimple | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(x)))
+	case string:
+		writeCBORHead(buf, cborText, uint64(len(x)))
+		buf.WriteString(x)
+	case []byte:
+		writeCBORHead(buf, cborBytes, uint64(len(x)))
+		buf.Write(x)
+	case time.Time:
+		// standard date/time string
+		s := x.Format(time.RFC3339Nano)
+		writeCBORHead(buf, cborTag, 0)
+		writeCBORHead(buf, cborText, uint64(len(s)))
+		buf.WriteString(s)
+
+	case []any:
+		writeCBORHead(buf, cborArray, uint64(len(x)))
+		for _, elem := range x {
+			if err := encodeCBOR(buf, elem); err != nil {
+				return err
+			}
+		}
+
+	case anyMap:
+		writeCBORHead(buf, cborMap, uint64(x.lenAny()))
+		return x.eachAny(func(key, value any) error {
+			if err := encodeCBOR(buf, key); err != nil {
+				return err
+			}
+			return encodeCBOR(buf, value)
+		})
+
+	default:
+		return fmt.Errorf("cbor: unsupported type %T", v)
+	}
+	return nil
+}
+
+// writeCBORHead writes the major type and the argument u in the smallest form.
+func writeCBORHead(buf *bytes.Buffer, major byte, u uint64) {
+	switch {
+	case u < 24:
+		buf.WriteByte(major | byte(u))
+	case u <= math.MaxUint8:
+		buf.Write([]byte{major | 24, byte(u)})
+	case u <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(u)))
+	case u <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(u)))
+	default:
+		buf.WriteByte(major | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, u))
+	}
+}
+
+type cborDecoder struct {
+	src []byte
+	pos int
+}
+
+var errCBORShort = errors.New("cbor: unexpected end of data")
+
+// indefinite is the argument of the indefinite-length items.
+const cborIndefinite = math.MaxUint64
+
+func (d *cborDecoder) next(n uint64) ([]byte, error) {
+	if n > uint64(len(d.src)-d.pos) {
+		return nil, errCBORShort
+	}
+	b := d.src[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// head reads the major type and the argument.
+// The argument of an indefinite-length item is cborIndefinite,
+// and that of a float is its bits.
+func (d *cborDecoder) head() (major byte, arg uint64, info byte, err error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major = b[0] & 0xe0
+	info = b[0] & 0x1f
+
+	switch {
+	case info < 24:
+		return major, uint64(info), info, nil
+	case info <= 27:
+		b, err := d.next(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		for _, c := range b {
+			arg = arg<<8 | uint64(c)
+		}
+		return major, arg, info, nil
+	case info == 31 && major != cborUint && major != cborNegint && major != cborTag:
+		return major, cborIndefinite, info, nil
+	}
+	return 0, 0, 0, fmt.Errorf("cbor: invalid additional information %d", info)
+}
+
+// isBreak consumes the break code of an indefinite-length item if it comes.
+func (d *cborDecoder) isBreak() bool {
+	if d.pos < len(d.src) && d.src[d.pos] == 0xff {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// readMap reads a map calling fn for each entry.
+func (d *cborDecoder) readMap(fn func(key, value any) error) error {
+	major, n, _, err := d.head()
+	if err != nil {
+		return err
+	}
+	if major != cborMap {
+		return errors.New("cbor: not a map")
+	}
+
+	for i := uint64(0); n == cborIndefinite || i < n; i++ {
+		if n == cborIndefinite && d.isBreak() {
+			break
+		}
+
+		key, err := d.read()
+		if err != nil {
+			return err
+		}
+		value, err := d.read()
+		if err != nil {
+			return err
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *cborDecoder) read() (any, error) {
+	if d.pos >= len(d.src) {
+		return nil, errCBORShort
+	}
+
+	if d.src[d.pos]&0xe0 == cborMap {
+		om := New[string, any]()
+		err := d.readMap(func(key, value any) error {
+			k, err := formatKey(key)
+			if err != nil {
+				return err
+			}
+			om.Set(k, value)
+			return nil
+		})
+		return om, err
+	}
+
+	major, arg, info, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborUint:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case cborNegint:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflows")
+		}
+		return -1 - int64(arg), nil
+
+	case cborBytes, cborText:
+		b, err := d.readString(major, arg)
+		if err != nil {
+			return nil, err
+		}
+		if major == cborText {
+			return string(b), nil
+		}
+		return b, nil
+
+	case cborArray:
+		if arg != cborIndefinite && arg > uint64(len(d.src)-d.pos) {
+			// each element takes a byte at least
+			return nil, errCBORShort
+		}
+		list := []any{}
+		for i := uint64(0); arg == cborIndefinite || i < arg; i++ {
+			if arg == cborIndefinite && d.isBreak() {
+				break
+			}
+			v, err := d.read()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+
+	case cborTag:
+		v, err := d.read()
+		if err != nil {
+			return nil, err
+		}
+		return cborTagged(arg, v)
+	}
+
+	// cborSimple
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23: // null, undefined
+		return nil, nil
+	case 25:
+		return halfFloat(uint16(arg)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	}
+	return nil, fmt.Errorf("cbor: unsupported simple value %d", arg)
+}
+
+// readString reads the content of a byte or text string, concatenating the chunks of an indefinite-length one.
+func (d *cborDecoder) readString(major byte, n uint64) ([]byte, error) {
+	if n != cborIndefinite {
+		b, err := d.next(n)
+		return append([]byte(nil), b...), err
+	}
+
+	var b []byte
+	for !d.isBreak() {
+		chunkMajor, chunkLen, _, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkLen == cborIndefinite {
+			return nil, errors.New("cbor: invalid chunk")
+		}
+		chunk, err := d.next(chunkLen)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+	return b, nil
+}
+
+// cborTagged interprets the tags of date/time, and ignores the others.
+func cborTagged(tag uint64, v any) (any, error) {
+	switch tag {
+	case 0:
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("cbor: invalid date/time string")
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case 1:
+		switch x := v.(type) {
+		case int64:
+			return time.Unix(x, 0), nil
+		case uint64:
+			return time.Unix(int64(x), 0), nil
+		case float64:
+			sec, frac := math.Modf(x)
+			return time.Unix(int64(sec), int64(frac*1e9)), nil
+		}
+		return nil, errors.New("cbor: invalid epoch date/time")
+	}
+	return v, nil
+}
+
+func halfFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
@@ -0,0 +1,80 @@
+package orderedmap_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestCBOR(t *testing.T) {
+	sub := orderedmap.New[string, any]()
+	sub.Set("y", -1)
+	sub.Set("x", []any{1, "a"})
+
+	m := orderedmap.New[string, any]()
+	m.Set("b", true)
+	m.Set("a", sub)
+	m.Set("c", nil)
+
+	b, err := m.MarshalCBOR()
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, b, []byte{
+		0xa3,
+		0x61, 'b', 0xf5,
+		0x61, 'a', 0xa2,
+		/**/ 0x61, 'y', 0x20,
+		/**/ 0x61, 'x', 0x82, 0x01, 0x61, 'a',
+		0x61, 'c', 0xf6,
+	})
+
+	m2 := orderedmap.New[string, any]()
+	err = m2.UnmarshalCBOR(b)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m2.Keys(), []string{"b", "a", "c"})
+	sub2 := m2.GetDefault("a", nil).(*orderedmap.OrderedMap[string, any])
+	gotwant.Test(t, sub2.Keys(), []string{"y", "x"})
+	gotwant.Test(t, sub2.GetDefault("y", nil), int64(-1))
+	gotwant.Test(t, sub2.GetDefault("x", nil), []any{int64(1), "a"})
+
+	t.Run("Typed", func(t *testing.T) {
+		now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+		m := orderedmap.New[int, time.Time]()
+		m.Set(300, now)
+		m.Set(-5, now.Add(time.Hour))
+
+		b, err := m.MarshalCBOR()
+		gotwant.TestError(t, err, nil)
+
+		m2 := orderedmap.New[int, time.Time]()
+		err = m2.UnmarshalCBOR(b)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m2.Keys(), []int{300, -5})
+		gotwant.Test(t, m2.GetDefault(300, time.Time{}).Equal(now), true)
+	})
+
+	t.Run("Indefinite", func(t *testing.T) {
+		// {_ "z": [_ 1.5(half), 2], "a": (_ h'01', h'02')}
+		b := []byte{
+			0xbf,
+			0x61, 'z', 0x9f, 0xf9, 0x3e, 0x00, 0x02, 0xff,
+			0x61, 'a', 0x5f, 0x41, 0x01, 0x41, 0x02, 0xff,
+			0xff,
+		}
+		m := orderedmap.New[string, any]()
+		err := m.UnmarshalCBOR(b)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"z", "a"})
+		gotwant.Test(t, m.GetDefault("z", nil), []any{1.5, int64(2)})
+		gotwant.Test(t, m.GetDefault("a", nil), []byte{1, 2})
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		m := orderedmap.New[string, any]()
+		gotwant.TestError(t, m.UnmarshalCBOR([]byte{0x82, 0x01, 0x02}), "not a map")
+		gotwant.TestError(t, m.UnmarshalCBOR([]byte{0xa1, 0x61}), "unexpected end")
+		gotwant.TestError(t, m.UnmarshalCBOR([]byte{0xa0, 0x00}), "extra data")
+	})
+}
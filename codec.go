@@ -0,0 +1,326 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// anyMap is implemented by every *OrderedMap, whatever K and V are,
+// so that the encoders can walk nested maps.
+type anyMap interface {
+	lenAny() int
+	eachAny(fn func(key, value any) error) error
+
+	// comments (WithComments)
+	eachNote(fn func(lines []string, key, value any) error) error
+	trailerLines() []string
+}
+
+func (m *OrderedMap[K, V]) lenAny() int {
+	return m.Len()
+}
+
+func (m *OrderedMap[K, V]) eachAny(fn func(key, value any) error) error {
+	if m == nil {
+		return nil
+	}
+
+	for e := m.first(); e != nil; e = e.next() {
+		if err := fn(e.key, e.v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plainValue converts v into one of
+// nil, bool, string, int64, uint64, float64, time.Time, []byte, []any and anyMap,
+// so that the encoders need to know only them.
+//
+// Slices are converted deeply, maps are not; the values of an anyMap are left to the walker.
+// Go maps become maps ordered by their keys, and structs are converted through encoding/json.
+func plainValue(v any) (any, error) {
+	switch x := v.(type) {
+	case nil, bool, string, int64, uint64, float64, time.Time, []byte:
+		return x, nil
+	case json.Number:
+		return plainNumber(x)
+	case anyMap:
+		if rv := reflect.ValueOf(x); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil, nil
+		}
+		return x, nil
+	case encoding.TextMarshaler:
+		if rv := reflect.ValueOf(x); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil, nil
+		}
+		b, err := x.MarshalText()
+		return string(b), err
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return plainValue(rv.Elem().Interface())
+
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes(), nil
+		}
+		fallthrough
+	case reflect.Array:
+		list := make([]any, rv.Len())
+		for i := range list {
+			p, err := plainValue(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			list[i] = p
+		}
+		return list, nil
+
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		type kv struct {
+			key   string
+			value any
+		}
+		pairs := make([]kv, 0, rv.Len())
+		for iter := rv.MapRange(); iter.Next(); {
+			k, err := formatKey(iter.Key().Interface())
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, kv{k, iter.Value().Interface()})
+		}
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].key < pairs[j].key })
+
+		om := New[string, any](WithCapacity(len(pairs)))
+		for _, p := range pairs {
+			om.Set(p.key, p.value)
+		}
+		return om, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return plainJSON(b)
+}
+
+// plainJSON decodes the JSON value b like plainValue, objects into ordered maps.
+func plainJSON(b []byte) (any, error) {
+	b = bytes.TrimSpace(b)
+
+	switch {
+	case len(b) > 0 && b[0] == '{':
+		raws := New[string, json.RawMessage]()
+		if err := raws.UnmarshalJSON(b); err != nil {
+			return nil, err
+		}
+
+		om := New[string, any](WithCapacity(raws.Len()))
+		for e := raws.first(); e != nil; e = e.next() {
+			p, err := plainJSON(e.v)
+			if err != nil {
+				return nil, err
+			}
+			om.Set(e.key, p)
+		}
+		return om, nil
+
+	case len(b) > 0 && b[0] == '[':
+		var raws []json.RawMessage
+		if err := json.Unmarshal(b, &raws); err != nil {
+			return nil, err
+		}
+
+		list := make([]any, len(raws))
+		for i, raw := range raws {
+			p, err := plainJSON(raw)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = p
+		}
+		return list, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return plainValue(v)
+}
+
+func plainNumber(n json.Number) (any, error) {
+	if i, err := n.Int64(); err == nil {
+		return i, nil
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return u, nil
+	}
+	return n.Float64()
+}
+
+// formatKey returns the text form of a key.
+func formatKey(key any) (string, error) {
+	if s, err := marshalKeyText(key); err == nil {
+		return s, nil
+	}
+
+	rv := reflect.ValueOf(key)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		return fmt.Sprint(key), nil
+	}
+	return "", fmt.Errorf("unsupported key type %T", key)
+}
+
+// parseText is the reverse of formatKey and scalarText.
+func parseText[T any](s string) (T, error) {
+	var t T
+	err := parseTextValue(reflect.ValueOf(&t).Elem(), s)
+	return t, err
+}
+
+// parseTextValue parses s into rv.
+func parseTextValue(rv reflect.Value, s string) error {
+	if u, ok := rv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return fmt.Errorf("unsupported type %v", rv.Type())
+		}
+		rv.Set(reflect.ValueOf(s))
+	default:
+		return fmt.Errorf("unsupported type %v", rv.Type())
+	}
+	return nil
+}
+
+// scalarText returns the text of v if v is a scalar.
+func scalarText(v any) (string, bool, error) {
+	p, err := plainValue(v)
+	if err != nil {
+		return "", false, err
+	}
+
+	switch x := p.(type) {
+	case string:
+		return x, true, nil
+	case bool:
+		return strconv.FormatBool(x), true, nil
+	case int64:
+		return strconv.FormatInt(x, 10), true, nil
+	case uint64:
+		return strconv.FormatUint(x, 10), true, nil
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64), true, nil
+	case time.Time:
+		return x.Format(time.RFC3339Nano), true, nil
+	}
+	return "", false, nil
+}
+
+// setPlain sets a decoded (like plainValue returns) key-value pair, converting them into K and V.
+func (m *OrderedMap[K, V]) setPlain(key, value any) error {
+	k, ok := key.(K)
+	if !ok {
+		s, err := formatKey(key)
+		if err != nil {
+			return err
+		}
+		k, err = parseText[K](s)
+		if err != nil {
+			return err
+		}
+	}
+
+	v, err := convertValue[V](value)
+	if err != nil {
+		return err
+	}
+	return m.setDecoded(k, v)
+}
+
+// convertValue converts a decoded value (like plainValue returns) into V,
+// as it is if possible, otherwise through encoding/json.
+func convertValue[V any](x any) (V, error) {
+	if v, ok := x.(V); ok {
+		return v, nil
+	}
+
+	var v V
+	if x == nil {
+		return v, nil
+	}
+
+	b, err := json.Marshal(x)
+	if err != nil {
+		return v, err
+	}
+	err = json.Unmarshal(b, &v)
+	return v, err
+}
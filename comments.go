@@ -0,0 +1,277 @@
+package orderedmap
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// note is what a round-trip decoding keeps about an entry besides its value.
+type note struct {
+	// comment and blank lines before the entry, as they are
+	lines []string
+
+	// nodes as decoded by UnmarshalYAML
+	yamlKey, yamlValue *yaml.Node
+}
+
+// WithComments makes the decoders of dotenv, properties and INI keep
+// the comment and blank lines attached to the following key,
+// and the encoders write them back.
+// Comments at the end of a line are not kept, nor is the "export" prefix of dotenv.
+//
+// For YAML, the nodes of each key and value are kept with their comments and styles.
+// MarshalYAML writes untouched values as they were,
+// and edited ones with the comments and the style of the original.
+// Blank lines are not kept, since yaml.v3 nodes do not carry them.
+func WithComments(b bool) Option {
+	return func(o *options) {
+		o.comments = b
+	}
+}
+
+// Comments returns the comment and blank lines before key, as they are in the document.
+func (m *OrderedMap[K, V]) Comments(key K) []string {
+	if m == nil {
+		return nil
+	}
+
+	e, found := m.m[m.mapKey(key)]
+	if !found || e.note == nil {
+		return nil
+	}
+	return append([]string(nil), e.note.lines...)
+}
+
+// SetComments replaces the lines written before key by the encoders of dotenv, properties and INI.
+// The lines must be comments or blank in the format.
+// It returns false if key is not found.
+func (m *OrderedMap[K, V]) SetComments(key K, lines []string) bool {
+	if m == nil {
+		return false
+	}
+
+	e, found := m.m[m.mapKey(key)]
+	if !found {
+		return false
+	}
+	if e.note == nil {
+		e.note = &note{}
+	}
+	e.note.lines = append([]string(nil), lines...)
+	return true
+}
+
+func (m *OrderedMap[K, V]) eachNote(fn func(lines []string, key, value any) error) error {
+	if m == nil {
+		return nil
+	}
+
+	for e := m.first(); e != nil; e = e.next() {
+		var lines []string
+		if e.note != nil {
+			lines = e.note.lines
+		}
+		if err := fn(lines, e.key, e.v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *OrderedMap[K, V]) trailerLines() []string {
+	if m == nil {
+		return nil
+	}
+	return m.trailer
+}
+
+// textMap is implemented by every *OrderedMap, so that the decoders can make nested maps of V.
+type textMap interface {
+	reset()
+	keepComments(b bool)
+	setText(key, value string, lines []string) error
+	setTrailer(lines []string)
+}
+
+func (m *OrderedMap[K, V]) keepComments(b bool) {
+	m.comments = b
+}
+
+// newNested returns a new map as V keeping comments like m, if V is a *OrderedMap.
+func (m *OrderedMap[K, V]) newNested() (V, textMap, bool) {
+	var v V
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return v, nil, false
+	}
+
+	nested, ok := reflect.New(t.Elem()).Interface().(textMap)
+	if !ok {
+		return v, nil, false
+	}
+	nested.reset()
+	nested.keepComments(m.comments)
+	v, ok = nested.(V)
+	return v, nested, ok
+}
+
+// setText sets key and value decoded from text, with the lines before them.
+func (m *OrderedMap[K, V]) setText(key, value string, lines []string) error {
+	k, err := parseText[K](key)
+	if err != nil {
+		return err
+	}
+	v, err := parseText[V](value)
+	if err != nil {
+		return err
+	}
+
+	if err := m.setDecoded(k, v); err != nil {
+		return err
+	}
+	m.attachLines(k, lines)
+	return nil
+}
+
+// attachLines adds lines before key if m keeps comments.
+func (m *OrderedMap[K, V]) attachLines(key K, lines []string) {
+	if !m.comments || len(lines) == 0 {
+		return
+	}
+
+	if e, found := m.m[m.mapKey(key)]; found {
+		if e.note == nil {
+			e.note = &note{}
+		}
+		e.note.lines = append(e.note.lines, lines...)
+	}
+}
+
+// setTrailer keeps lines after the last entry if m keeps comments.
+func (m *OrderedMap[K, V]) setTrailer(lines []string) {
+	if m.comments && len(lines) != 0 {
+		m.trailer = lines
+	}
+}
+
+// textLines splits b into lines without line terminators.
+func textLines(b []byte) []string {
+	s := strings.ReplaceAll(string(b), "\r\n", "\n")
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func writeLines(buf *bytes.Buffer, lines []string) {
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+}
+
+// keepYAML keeps the nodes of key if m keeps comments.
+func (m *OrderedMap[K, V]) keepYAML(key K, keyNode, valueNode *yaml.Node) {
+	if !m.comments {
+		return
+	}
+
+	if e, found := m.m[m.mapKey(key)]; found {
+		if e.note == nil {
+			e.note = &note{}
+		}
+		e.note.yamlKey = keyNode
+		e.note.yamlValue = valueNode
+	}
+}
+
+// yamlMeta returns a copy of n without its content.
+func yamlMeta(n *yaml.Node) *yaml.Node {
+	meta := *n
+	meta.Content = nil
+	return &meta
+}
+
+// yamlNode returns the mapping node of m, made of the kept nodes as far as possible.
+func (m *OrderedMap[K, V]) yamlNode() (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if m.yamlMeta != nil {
+		node = yamlMeta(m.yamlMeta)
+	}
+	node.Content = make([]*yaml.Node, 0, 2*len(m.m))
+
+	for e := m.first(); e != nil; e = e.next() {
+		var kept note
+		if e.note != nil {
+			kept = *e.note
+		}
+
+		keyNode := kept.yamlKey
+		if keyNode == nil {
+			keyNode = &yaml.Node{}
+			if err := keyNode.Encode(e.key); err != nil {
+				return nil, err
+			}
+		}
+
+		valueNode, err := yamlValueNode(e.v, kept.yamlValue)
+		if err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content, keyNode, valueNode)
+	}
+	return node, nil
+}
+
+// yamlValueNode returns kept if v is not changed from it,
+// otherwise a new node of v with the comments and the style of kept.
+func yamlValueNode[V any](v V, kept *yaml.Node) (*yaml.Node, error) {
+	_, nested := any(v).(anyMap) // keeps its own nodes
+	if kept != nil && !nested {
+		var old V
+		if err := kept.Decode(&old); err == nil && reflect.DeepEqual(old, v) {
+			return kept, nil
+		}
+	}
+
+	node, err := encodeYAMLNode(v)
+	if err != nil {
+		return nil, err
+	}
+	if kept == nil {
+		return node, nil
+	}
+
+	node.HeadComment = kept.HeadComment
+	node.LineComment = kept.LineComment
+	node.FootComment = kept.FootComment
+	if node.Kind == kept.Kind && (node.Kind != yaml.ScalarNode || node.Tag == kept.Tag) {
+		node.Style = kept.Style
+	}
+	return node, nil
+}
+
+// encodeYAMLNode is node.Encode(v), but keeps the comments of a node that MarshalYAML returns.
+func encodeYAMLNode(v any) (*yaml.Node, error) {
+	if marshaler, ok := v.(yaml.Marshaler); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() != reflect.Pointer || !rv.IsNil() {
+			out, err := marshaler.MarshalYAML()
+			if err != nil {
+				return nil, err
+			}
+			if node, ok := out.(*yaml.Node); ok {
+				return node, nil
+			}
+			v = out
+		}
+	}
+
+	node := &yaml.Node{}
+	err := node.Encode(v)
+	return node, err
+}
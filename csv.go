@@ -0,0 +1,136 @@
+package orderedmap
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// CSVColumns decides the columns WriteCSV writes.
+type CSVColumns int
+
+const (
+	// CSVColumnsFirst takes the columns from the keys of the first map. Other keys are not written.
+	CSVColumnsFirst CSVColumns = iota
+	// CSVColumnsUnion takes the union of the keys of all the maps, in first-seen order.
+	CSVColumnsUnion
+)
+
+// ReadCSV reads the records of r into maps, the header row giving the keys in column order.
+// For TSV, set r.Comma to '\t'.
+func ReadCSV(r *csv.Reader) ([]*OrderedMap[string, string], error) {
+	return ReadCSVFunc(r, func(key, field string) (string, error) {
+		return field, nil
+	})
+}
+
+// ReadCSVFunc is ReadCSV converting each field by conv.
+func ReadCSVFunc[V any](r *csv.Reader, conv func(key, field string) (V, error)) ([]*OrderedMap[string, V], error) {
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	header = append([]string(nil), header...) // r may reuse the record
+
+	seen := make(map[string]bool, len(header))
+	for _, key := range header {
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate column %q", key)
+		}
+		seen[key] = true
+	}
+
+	var rows []*OrderedMap[string, V]
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) > len(header) {
+			line, _ := r.FieldPos(len(header))
+			return nil, fmt.Errorf("line %d: more fields than the header", line)
+		}
+
+		row := New[string, V](WithCapacity(len(record)))
+		for i, field := range record {
+			v, err := conv(header[i], field)
+			if err != nil {
+				line, col := r.FieldPos(i)
+				return nil, fmt.Errorf("line %d, column %d (%s): %w", line, col, header[i], err)
+			}
+			row.Set(header[i], v)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// WriteCSV writes the header row and the maps as records, ordering the columns by columns.
+// Missing keys are written as empty fields.
+// For TSV, set w.Comma to '\t'.
+func WriteCSV(w *csv.Writer, rows []*OrderedMap[string, string], columns CSVColumns) error {
+	return WriteCSVFunc(w, rows, columns, func(key, value string) (string, error) {
+		return value, nil
+	})
+}
+
+// WriteCSVFunc is WriteCSV converting each value by format.
+func WriteCSVFunc[V any](w *csv.Writer, rows []*OrderedMap[string, V], columns CSVColumns, format func(key string, value V) (string, error)) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var header []string
+	switch columns {
+	case CSVColumnsFirst:
+		for _, row := range rows {
+			if row != nil {
+				header = row.Keys()
+				break
+			}
+		}
+	case CSVColumnsUnion:
+		seen := make(map[string]bool)
+		for _, row := range rows {
+			for _, key := range row.Keys() {
+				if !seen[key] {
+					seen[key] = true
+					header = append(header, key)
+				}
+			}
+		}
+	default:
+		return errors.New("unknown CSVColumns")
+	}
+
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(header))
+	for _, row := range rows {
+		for i, key := range header {
+			record[i] = ""
+			if v, found := row.Get(key); found {
+				s, err := format(key, v)
+				if err != nil {
+					return fmt.Errorf("column %s: %w", key, err)
+				}
+				record[i] = s
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,93 @@
+package orderedmap_test
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestReadCSV(t *testing.T) {
+	rows, err := orderedmap.ReadCSV(csv.NewReader(strings.NewReader("name,age,city\nbob,30,\"New York, NY\"\nalice,25,Tokyo\n")))
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, len(rows), 2)
+	gotwant.Test(t, rows[0].Keys(), []string{"name", "age", "city"})
+	gotwant.Test(t, rows[0].GetDefault("city", ""), "New York, NY")
+	gotwant.Test(t, rows[1].Values(), []string{"alice", "25", "Tokyo"})
+
+	t.Run("TSV", func(t *testing.T) {
+		r := csv.NewReader(strings.NewReader("b\ta\n1\t2\n"))
+		r.Comma = '\t'
+		rows, err := orderedmap.ReadCSV(r)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, rows[0].Keys(), []string{"b", "a"})
+		gotwant.Test(t, rows[0].GetDefault("a", ""), "2")
+	})
+
+	t.Run("Func", func(t *testing.T) {
+		r := csv.NewReader(strings.NewReader("x,y\n1,2\n3,oops\n"))
+		_, err := orderedmap.ReadCSVFunc(r, func(key, field string) (int, error) {
+			return strconv.Atoi(field)
+		})
+		gotwant.TestError(t, err, "line 3, column 3 (y)")
+
+		r = csv.NewReader(strings.NewReader("x,y\n1,2\n"))
+		rows, err := orderedmap.ReadCSVFunc(r, func(key, field string) (int, error) {
+			return strconv.Atoi(field)
+		})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, rows[0].Values(), []int{1, 2})
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := orderedmap.ReadCSV(csv.NewReader(strings.NewReader("a,a\n1,2\n")))
+		gotwant.TestError(t, err, `duplicate column "a"`)
+
+		rows, err := orderedmap.ReadCSV(csv.NewReader(strings.NewReader("")))
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, len(rows), 0)
+	})
+}
+
+func TestWriteCSV(t *testing.T) {
+	r1 := orderedmap.New[string, string]()
+	r1.Set("name", "bob")
+	r1.Set("city", "New York, NY")
+	r2 := orderedmap.New[string, string]()
+	r2.Set("age", "25")
+	r2.Set("name", "alice")
+	rows := []*orderedmap.OrderedMap[string, string]{r1, r2}
+
+	t.Run("First", func(t *testing.T) {
+		sb := &strings.Builder{}
+		err := orderedmap.WriteCSV(csv.NewWriter(sb), rows, orderedmap.CSVColumnsFirst)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, sb.String(), "name,city\nbob,\"New York, NY\"\nalice,\n")
+	})
+
+	t.Run("Union", func(t *testing.T) {
+		sb := &strings.Builder{}
+		w := csv.NewWriter(sb)
+		w.Comma = '\t'
+		err := orderedmap.WriteCSV(w, rows, orderedmap.CSVColumnsUnion)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, sb.String(), "name\tcity\tage\nbob\tNew York, NY\t\nalice\t\t25\n")
+	})
+
+	t.Run("Func", func(t *testing.T) {
+		m := orderedmap.New[string, float64]()
+		m.Set("x", 1.5)
+		m.Set("y", 2)
+
+		sb := &strings.Builder{}
+		err := orderedmap.WriteCSVFunc(csv.NewWriter(sb), []*orderedmap.OrderedMap[string, float64]{m}, orderedmap.CSVColumnsFirst,
+			func(key string, v float64) (string, error) {
+				return strconv.FormatFloat(v, 'f', 2, 64), nil
+			})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, sb.String(), "x,y\n1.50,2.00\n")
+	})
+}
@@ -0,0 +1,172 @@
+package orderedmap
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// MarshalDotenv encodes m as a .env file of KEY=value lines, in order.
+// Values are quoted if needed.
+func (m *OrderedMap[K, V]) MarshalDotenv() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if m == nil {
+		return buf.Bytes(), nil
+	}
+
+	err := m.eachNote(func(lines []string, key, value any) error {
+		k, err := formatKey(key)
+		if err != nil {
+			return err
+		}
+		v, ok, err := scalarText(value)
+		if err != nil {
+			return err
+		}
+		if !ok && value != nil {
+			return fmt.Errorf("dotenv: unsupported type %T", value)
+		}
+
+		writeLines(buf, lines)
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		buf.WriteString(quoteDotenv(v))
+		buf.WriteByte('\n')
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	writeLines(buf, m.trailer)
+	return buf.Bytes(), nil
+}
+
+func quoteDotenv(s string) string {
+	plain := true
+	for _, c := range s {
+		if !('A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.ContainsRune("_-.,:/@+%", c)) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return s
+	}
+
+	if !strings.ContainsAny(s, "'\n\r") {
+		return "'" + s + "'"
+	}
+
+	// $ too, not to be expanded by loaders
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// UnmarshalDotenv decodes a .env file into m.
+//
+// Lines are KEY=value, optionally prefixed with "export".
+// Values may be 'single-quoted' (as they are), or "double-quoted" (with escapes, across lines).
+// Unquoted values end at " #".
+func (m *OrderedMap[K, V]) UnmarshalDotenv(b []byte) error {
+	defer m.beginDecode()()
+
+	lines := textLines(b)
+	var pending []string
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || line[0] == '#' {
+			pending = append(pending, lines[i])
+			continue
+		}
+		lineno := i + 1
+
+		if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+			line = strings.TrimSpace(line[len("export"):])
+		}
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return fmt.Errorf("dotenv: line %d: = is required", lineno)
+		}
+		key := strings.TrimSpace(line[:eq])
+		value := strings.TrimLeft(line[eq+1:], " \t")
+
+		if value != "" && (value[0] == '"' || value[0] == '\'') {
+			// may continue to the following lines
+			quote := value[0]
+			text := value[1:]
+			for {
+				if end := closingQuote(text, quote); end >= 0 {
+					after := strings.TrimSpace(text[end+1:])
+					if after != "" && after[0] != '#' {
+						return fmt.Errorf("dotenv: line %d: unexpected %q", lineno, after)
+					}
+					value = text[:end]
+					break
+				}
+				i++
+				if i >= len(lines) {
+					return fmt.Errorf("dotenv: line %d: %c is required", lineno, quote)
+				}
+				text += "\n" + lines[i]
+			}
+			if quote == '"' {
+				value = unescapeDotenv(value)
+			}
+
+		} else {
+			if hash := strings.Index(value, " #"); hash >= 0 {
+				value = value[:hash]
+			}
+			value = strings.TrimSpace(value)
+		}
+
+		if err := m.setText(key, value, pending); err != nil {
+			return fmt.Errorf("dotenv: line %d: %w", lineno, err)
+		}
+		pending = nil
+	}
+
+	m.setTrailer(pending)
+	return nil
+}
+
+// closingQuote returns the index of the closing quote in s, or -1.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeDotenv(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case '"', '\\', '$':
+			sb.WriteByte(s[i])
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package orderedmap_test
+
+import (
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+const dotenvDoc = `# database
+DB_HOST=localhost
+DB_PORT=5432 # default
+
+export SECRET='p@ss w#rd'
+MULTI="line1
+line2 \"quoted\""
+
+# end
+`
+
+func TestUnmarshalDotenv(t *testing.T) {
+	m := orderedmap.New[string, string]()
+	err := m.UnmarshalDotenv([]byte(dotenvDoc))
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m.Keys(), []string{"DB_HOST", "DB_PORT", "SECRET", "MULTI"})
+	gotwant.Test(t, m.Values(), []string{"localhost", "5432", "p@ss w#rd", "line1\nline2 \"quoted\""})
+	gotwant.Test(t, len(m.Comments("DB_HOST")), 0)
+
+	t.Run("Typed", func(t *testing.T) {
+		m := orderedmap.New[string, int]()
+		err := m.UnmarshalDotenv([]byte("B=2\nA=1\n"))
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Values(), []int{2, 1})
+
+		err = m.UnmarshalDotenv([]byte("B=x\n"))
+		gotwant.TestError(t, err, "line 1")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		m := orderedmap.New[string, string]()
+		gotwant.TestError(t, m.UnmarshalDotenv([]byte("A\n")), "= is required")
+		gotwant.TestError(t, m.UnmarshalDotenv([]byte("A=\"open\n")), `" is required`)
+	})
+}
+
+func TestMarshalDotenv(t *testing.T) {
+	m := orderedmap.New[string, any]()
+	m.Set("PLAIN", "abc/def")
+	m.Set("SPACE", "a b")
+	m.Set("QUOTE", "it's\n")
+	m.Set("DOLLAR", "it's $HOME")
+	m.Set("NUM", 10)
+	m.Set("EMPTY", nil)
+
+	b, err := m.MarshalDotenv()
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b), "PLAIN=abc/def\nSPACE='a b'\nQUOTE=\"it's\\n\"\nDOLLAR=\"it's \\$HOME\"\nNUM=10\nEMPTY=\n")
+
+	m2 := orderedmap.New[string, any]()
+	err = m2.UnmarshalDotenv(b)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m2.Values(), []any{"abc/def", "a b", "it's\n", "it's $HOME", "10", ""})
+}
+
+func TestComments(t *testing.T) {
+	m := orderedmap.New[string, string](orderedmap.WithComments(true))
+	err := m.UnmarshalDotenv([]byte(dotenvDoc))
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m.Comments("DB_HOST"), []string{"# database"})
+	gotwant.Test(t, m.Comments("SECRET"), []string{""})
+
+	// edit
+	m.Set("DB_PORT", "15432")
+	m.Delete("MULTI")
+	m.Set("NEW", "1")
+	gotwant.Test(t, m.SetComments("NEW", []string{"# added"}), true)
+	gotwant.Test(t, m.SetComments("NONE", []string{"# none"}), false)
+	m.MoveToFront("SECRET")
+
+	b, err := m.MarshalDotenv()
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b), `
+SECRET='p@ss w#rd'
+# database
+DB_HOST=localhost
+DB_PORT=15432
+# added
+NEW=1
+
+# end
+`)
+}
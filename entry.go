@@ -0,0 +1,82 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Entry is a key-value pair.
+// In JSON, it is encoded as a 2-element array [key, value].
+type Entry[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+func (e Entry[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]any{e.Key, e.Value})
+}
+
+func (e *Entry[K, V]) UnmarshalJSON(b []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(b, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return errors.New("entry must be [key, value]")
+	}
+
+	if err := json.Unmarshal(pair[0], &e.Key); err != nil {
+		return err
+	}
+	return json.Unmarshal(pair[1], &e.Value)
+}
+
+// FromEntries returns a new map of entries.
+// A later entry overwrites the value of the same key.
+func FromEntries[K comparable, V any](entries ...Entry[K, V]) *OrderedMap[K, V] {
+	m := New[K, V]()
+	for _, e := range entries {
+		m.Set(e.Key, e.Value)
+	}
+	return m
+}
+
+// NewFrom returns a new map of keys[i]:values[i].
+// It panics if the lengths of keys and values differ.
+func NewFrom[K comparable, V any](keys []K, values []V) *OrderedMap[K, V] {
+	if len(keys) != len(values) {
+		panic("orderedmap: keys and values have different lengths")
+	}
+
+	m := New[K, V]()
+	for i, k := range keys {
+		m.Set(k, values[i])
+	}
+	return m
+}
+
+// Values returns the values in order.
+func (m *OrderedMap[K, V]) Values() []V {
+	if m == nil || m.root == nil {
+		return nil
+	}
+
+	values := make([]V, 0, len(m.m))
+	for e := m.first(); e != nil; e = e.next() {
+		values = append(values, e.v)
+	}
+	return values
+}
+
+// Entries returns the key-value pairs in order.
+func (m *OrderedMap[K, V]) Entries() []Entry[K, V] {
+	if m == nil || m.root == nil {
+		return nil
+	}
+
+	entries := make([]Entry[K, V], 0, len(m.m))
+	for e := m.first(); e != nil; e = e.next() {
+		entries = append(entries, Entry[K, V]{Key: e.key, Value: e.v})
+	}
+	return entries
+}
@@ -0,0 +1,67 @@
+package orderedmap_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestValues(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	gotwant.Test(t, len(m.Values()), 0)
+
+	m.Set("z", 1)
+	m.Set("a", 2)
+	m.Set("m", 3)
+	m.Delete("a")
+	gotwant.Test(t, m.Values(), []int{1, 3})
+}
+
+func TestEntries(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	m.Set("z", 1)
+	m.Set("a", 2)
+
+	entries := m.Entries()
+	gotwant.Test(t, entries, []orderedmap.Entry[string, int]{
+		{Key: "z", Value: 1},
+		{Key: "a", Value: 2},
+	})
+
+	entries[0].Value = 100
+	gotwant.Test(t, m.GetDefault("z", 0), 1)
+
+	b, err := json.Marshal(entries)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b), `[["z",100],["a",2]]`)
+
+	var decoded []orderedmap.Entry[int, string]
+	err = json.Unmarshal([]byte(`[[3,"san"],[1,"ichi"]]`), &decoded)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, orderedmap.FromEntries(decoded...).Keys(), []int{3, 1})
+
+	err = json.Unmarshal([]byte(`[[3,"san",1]]`), &decoded)
+	gotwant.TestError(t, err, "[key, value]")
+}
+
+func TestFromEntries(t *testing.T) {
+	m := orderedmap.FromEntries(
+		orderedmap.Entry[string, int]{Key: "b", Value: 1},
+		orderedmap.Entry[string, int]{Key: "a", Value: 2},
+		orderedmap.Entry[string, int]{Key: "b", Value: 3},
+	)
+	gotwant.Test(t, m.Keys(), []string{"b", "a"})
+	gotwant.Test(t, m.Values(), []int{3, 2})
+}
+
+func TestNewFrom(t *testing.T) {
+	m := orderedmap.NewFrom([]string{"b", "a"}, []int{1, 2})
+	gotwant.Test(t, m.Keys(), []string{"b", "a"})
+	gotwant.Test(t, m.Values(), []int{1, 2})
+
+	gotwant.TestPanic(t, func() {
+		orderedmap.NewFrom([]string{"b", "a"}, []int{1})
+	}, "orderedmap: keys and values have different lengths")
+}
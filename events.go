@@ -0,0 +1,168 @@
+package orderedmap
+
+// EventKind is the kind of a change of a map.
+type EventKind int
+
+const (
+	// EventInsert is a Set of a new key.
+	EventInsert EventKind = iota
+	// EventUpdate is a Set of an existing key.
+	EventUpdate
+	// EventDelete is a deletion of a key.
+	EventDelete
+	// EventMove is a move of a key by MoveTo, MoveToFront or MoveToBack.
+	EventMove
+	// EventSort is a reordering of the whole map by Sort, Reverse, ...
+	EventSort
+	// EventReset is a replacement of the whole content by UnmarshalJSON or UnmarshalYAML.
+	EventReset
+	// EventClear is a deletion of all the entries by Clear.
+	EventClear
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventInsert:
+		return "Insert"
+	case EventUpdate:
+		return "Update"
+	case EventDelete:
+		return "Delete"
+	case EventMove:
+		return "Move"
+	case EventSort:
+		return "Sort"
+	case EventReset:
+		return "Reset"
+	case EventClear:
+		return "Clear"
+	}
+	return "EventKind(?)"
+}
+
+// Event describes a change of a map.
+//
+// OldValue and OldIndex are set for EventUpdate, EventDelete and EventMove,
+// NewValue and NewIndex for EventInsert, EventUpdate and EventMove.
+// An index not set is -1.
+// Key and values are not set for EventSort, EventReset and EventClear.
+type Event[K comparable, V any] struct {
+	Kind EventKind
+
+	Key K
+
+	OldValue V
+	NewValue V
+
+	OldIndex int
+	NewIndex int
+}
+
+type observer[K comparable, V any] struct {
+	fn func(Event[K, V])
+}
+
+// OnChange registers fn to be called on every change of m.
+// fn is called synchronously after the change has been applied,
+// in the order of registration.
+//
+// Calling the returned function unregisters fn.
+func (m *OrderedMap[K, V]) OnChange(fn func(Event[K, V])) (unsubscribe func()) {
+	o := &observer[K, V]{fn: fn}
+	m.observers = append(m.observers[:len(m.observers):len(m.observers)], o)
+
+	return func() {
+		for i, oo := range m.observers {
+			if oo == o {
+				// copy, not to disturb emit in progress
+				observers := make([]*observer[K, V], 0, len(m.observers)-1)
+				observers = append(observers, m.observers[:i]...)
+				m.observers = append(observers, m.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// change is an Event with what is needed to revert it.
+type change[K comparable, V any] struct {
+	Event[K, V]
+
+	e *elem[K, V]
+
+	// orders of EventSort, EventReset and EventClear
+	before, after []*elem[K, V]
+}
+
+// tracking reports whether changes should be built.
+func (m *OrderedMap[K, V]) tracking() bool {
+	return !m.muted && (len(m.observers) > 0 || m.intx || m.history != nil)
+}
+
+func (m *OrderedMap[K, V]) changed(c change[K, V]) {
+	if m.muted {
+		return
+	}
+
+	if m.intx {
+		// notified on commit
+		m.txlog = append(m.txlog, c)
+		return
+	}
+
+	if m.history != nil {
+		m.history.record(c)
+	}
+
+	for _, o := range m.observers {
+		o.fn(c.Event)
+	}
+}
+
+// changedWhole notifies a change of the whole map.
+// before is the result of snapshot taken before the change.
+func (m *OrderedMap[K, V]) changedWhole(kind EventKind, before []*elem[K, V]) {
+	if !m.tracking() {
+		return
+	}
+
+	m.changed(change[K, V]{
+		Event: Event[K, V]{
+			Kind:     kind,
+			OldIndex: -1,
+			NewIndex: -1,
+		},
+		before: before,
+		after:  m.snapshot(),
+	})
+}
+
+// beginDecode clears m and mutes the changes until end is called,
+// which notifies them as one EventReset.
+func (m *OrderedMap[K, V]) beginDecode() (end func()) {
+	before := m.snapshot()
+	m.reset()
+	m.muted = true
+	return func() {
+		m.muted = false
+		m.changedWhole(EventReset, before)
+	}
+}
+
+// snapshot returns the entries in order if changes are tracked.
+func (m *OrderedMap[K, V]) snapshot() []*elem[K, V] {
+	if !m.tracking() {
+		return nil
+	}
+
+	return m.entries()
+}
+
+// restore replaces the entries with a snapshot.
+func (m *OrderedMap[K, V]) restore(order []*elem[K, V]) {
+	m.m = make(map[K]*elem[K, V], len(order))
+	for _, e := range order {
+		m.m[m.mapKey(e.key)] = e
+	}
+	m.rebuild(order)
+}
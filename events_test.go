@@ -0,0 +1,100 @@
+package orderedmap_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestOnChange(t *testing.T) {
+	m := orderedmap.New[string, int]()
+
+	var events []string
+	unsubscribe := m.OnChange(func(ev orderedmap.Event[string, int]) {
+		events = append(events, fmt.Sprintf("%v %v %v->%v %v->%v", ev.Kind, ev.Key, ev.OldValue, ev.NewValue, ev.OldIndex, ev.NewIndex))
+	})
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+	m.Set("a", 10)
+	m.PreserveOrder(false)
+	m.Set("a", 100)
+	m.Delete("b")
+	m.Delete("zzz")
+	m.MoveToFront("a")
+	m.Sort(func(a, b string) bool { return a > b })
+
+	gotwant.Test(t, events, []string{
+		"Insert a 0->1 -1->0",
+		"Insert b 0->2 -1->1",
+		"Insert c 0->3 -1->2",
+		"Update a 1->10 0->0",
+		"Update a 10->100 0->2",
+		"Delete b 2->0 0->-1",
+		"Move a 100->100 1->0",
+		"Sort  0->0 -1->-1",
+	})
+
+	events = nil
+	orderedmap.DeleteFunc(m, func(string, int) bool { return true })
+	gotwant.Test(t, events, []string{
+		"Delete c 3->0 0->-1",
+		"Delete a 100->0 0->-1",
+	})
+
+	events = nil
+	err := json.Unmarshal([]byte(`{"x":1,"y":2}`), m)
+	gotwant.TestError(t, err, nil)
+	err = yaml.Unmarshal([]byte("z: 3\n"), m)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, events, []string{
+		"Reset  0->0 -1->-1",
+		"Reset  0->0 -1->-1",
+	})
+
+	events = nil
+	m.Clear()
+	gotwant.Test(t, events, []string{"Clear  0->0 -1->-1"})
+
+	events = nil
+	unsubscribe()
+	unsubscribe()
+	m.Set("q", 0)
+	gotwant.Test(t, len(events), 0)
+}
+
+func TestOnChangeOrder(t *testing.T) {
+	m := orderedmap.New[int, int]()
+
+	var calls []string
+	var unsubscribe2 func()
+	m.OnChange(func(ev orderedmap.Event[int, int]) {
+		// the change has been applied
+		gotwant.Test(t, m.GetDefault(ev.Key, -1), ev.NewValue)
+		calls = append(calls, "1")
+
+		// unsubscribing during an emit does not skip the others
+		if unsubscribe2 != nil {
+			unsubscribe2()
+		}
+	})
+	unsubscribe2 = m.OnChange(func(ev orderedmap.Event[int, int]) {
+		calls = append(calls, "2")
+	})
+	m.OnChange(func(ev orderedmap.Event[int, int]) {
+		calls = append(calls, "3")
+	})
+
+	m.Set(1, 1)
+	gotwant.Test(t, calls, []string{"1", "2", "3"})
+
+	calls = nil
+	m.Set(1, 2)
+	gotwant.Test(t, calls, []string{"1", "3"})
+}
@@ -0,0 +1,173 @@
+package orderedmap
+
+import "errors"
+
+// CollisionPolicy decides what MapKeys does when two keys are mapped to the same new key.
+type CollisionPolicy int
+
+const (
+	// CollisionKeepFirst keeps the value of the first key.
+	CollisionKeepFirst CollisionPolicy = iota
+	// CollisionKeepLast keeps the value of the last key. The position follows PreserveOrder.
+	CollisionKeepLast
+	// CollisionError makes MapKeys fail with ErrKeyCollision.
+	CollisionError
+)
+
+var ErrKeyCollision = errors.New("key collision")
+
+// Filter returns a new map with the entries for which pred returns true.
+func Filter[K comparable, V any](m *OrderedMap[K, V], pred func(K, V) bool) *OrderedMap[K, V] {
+	if m == nil {
+		return nil
+	}
+
+	result := derive[K, V](m)
+	for e := m.first(); e != nil; e = e.next() {
+		if pred(e.key, e.v) {
+			result.Set(e.key, e.v)
+		}
+	}
+	return result
+}
+
+// MapValues returns a new map with the same keys and the values converted by fn.
+func MapValues[K comparable, V, W any](m *OrderedMap[K, V], fn func(K, V) W) *OrderedMap[K, W] {
+	if m == nil {
+		return nil
+	}
+
+	result := derive[K, W](m)
+	for e := m.first(); e != nil; e = e.next() {
+		result.Set(e.key, fn(e.key, e.v))
+	}
+	return result
+}
+
+// MapKeys returns a new map with the keys converted by fn.
+// Colliding keys are resolved by policy.
+func MapKeys[K, J comparable, V any](m *OrderedMap[K, V], fn func(K, V) J, policy CollisionPolicy) (*OrderedMap[J, V], error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	result := New[J, V]()
+	result.overwriteSeq = m.overwriteSeq
+	for e := m.first(); e != nil; e = e.next() {
+		v := e.v
+		j := fn(e.key, v)
+
+		if result.Contains(j) {
+			switch policy {
+			case CollisionKeepFirst:
+				continue
+			case CollisionError:
+				return nil, ErrKeyCollision
+			}
+		}
+		result.Set(j, v)
+	}
+	return result, nil
+}
+
+// Reduce folds the entries in order.
+func Reduce[K comparable, V, A any](m *OrderedMap[K, V], init A, fn func(acc A, key K, value V) A) A {
+	if m == nil {
+		return init
+	}
+
+	acc := init
+	for e := m.first(); e != nil; e = e.next() {
+		acc = fn(acc, e.key, e.v)
+	}
+	return acc
+}
+
+// Partition splits m into the entries for which pred returns true and the others.
+func Partition[K comparable, V any](m *OrderedMap[K, V], pred func(K, V) bool) (matched, unmatched *OrderedMap[K, V]) {
+	if m == nil {
+		return nil, nil
+	}
+
+	matched = derive[K, V](m)
+	unmatched = derive[K, V](m)
+	for e := m.first(); e != nil; e = e.next() {
+		if pred(e.key, e.v) {
+			matched.Set(e.key, e.v)
+		} else {
+			unmatched.Set(e.key, e.v)
+		}
+	}
+	return matched, unmatched
+}
+
+// GroupBy groups the entries by keyFn.
+// Groups are ordered by their first appearance.
+func GroupBy[K, G comparable, V any](m *OrderedMap[K, V], keyFn func(K, V) G) *OrderedMap[G, *OrderedMap[K, V]] {
+	if m == nil {
+		return nil
+	}
+
+	result := New[G, *OrderedMap[K, V]]()
+	for e := m.first(); e != nil; e = e.next() {
+		g := keyFn(e.key, e.v)
+
+		group, found := result.Get(g)
+		if !found {
+			group = derive[K, V](m)
+			result.Set(g, group)
+		}
+		group.Set(e.key, e.v)
+	}
+	return result
+}
+
+// DeleteFunc deletes the entries for which del returns true.
+func DeleteFunc[K comparable, V any](m *OrderedMap[K, V], del func(K, V) bool) {
+	if m == nil {
+		return
+	}
+
+	var changes []change[K, V]
+	kept := make([]*elem[K, V], 0, len(m.m))
+	for e := m.first(); e != nil; e = e.next() {
+		if !del(e.key, e.v) {
+			kept = append(kept, e)
+			continue
+		}
+
+		delete(m.m, m.mapKey(e.key))
+
+		if m.tracking() {
+			changes = append(changes, change[K, V]{
+				Event: Event[K, V]{
+					Kind:     EventDelete,
+					Key:      e.key,
+					OldValue: e.v,
+					OldIndex: len(kept),
+					NewIndex: -1,
+				},
+				e: e,
+			})
+		}
+	}
+	m.rebuild(kept)
+
+	m.beginStep()
+	for _, c := range changes {
+		m.changed(c)
+	}
+	m.endStep()
+}
+
+// derive returns an empty map configured like src.
+func derive[K comparable, W, V any](src *OrderedMap[K, V]) *OrderedMap[K, W] {
+	m := New[K, W]()
+	m.overwriteSeq = src.overwriteSeq
+	m.cmp = src.cmp
+	m.norm = src.norm
+	m.duplicate = src.duplicate
+	m.xml = src.xml
+	m.comments = src.comments
+	return m
+}
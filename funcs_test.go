@@ -0,0 +1,128 @@
+package orderedmap_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func newFuncsMap() *orderedmap.OrderedMap[string, int] {
+	m := orderedmap.New[string, int]()
+	m.Set("z", 1)
+	m.Set("b", 2)
+	m.Set("x", 3)
+	m.Set("a", 4)
+	return m
+}
+
+func TestFilter(t *testing.T) {
+	m := newFuncsMap()
+
+	f := orderedmap.Filter(m, func(k string, v int) bool {
+		return v%2 == 0
+	})
+	gotwant.Test(t, f.Keys(), []string{"b", "a"})
+	gotwant.Test(t, m.Keys(), []string{"z", "b", "x", "a"})
+
+	var nilm *orderedmap.OrderedMap[string, int]
+	gotwant.Test(t, orderedmap.Filter(nilm, func(string, int) bool { return true }).Len(), 0)
+}
+
+func TestMapValues(t *testing.T) {
+	m := newFuncsMap()
+
+	mv := orderedmap.MapValues(m, func(k string, v int) string {
+		return strings.Repeat(k, v)
+	})
+	gotwant.Test(t, mv.Keys(), []string{"z", "b", "x", "a"})
+	gotwant.Test(t, mv.GetDefault("x", ""), "xxx")
+}
+
+func TestMapKeys(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	m.Set("a", 1)
+	m.Set("B", 2)
+	m.Set("A", 3)
+	m.Set("c", 4)
+
+	t.Run("KeepFirst", func(t *testing.T) {
+		mk, err := orderedmap.MapKeys(m, func(k string, _ int) string { return strings.ToLower(k) }, orderedmap.CollisionKeepFirst)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, mk.Keys(), []string{"a", "b", "c"})
+		gotwant.Test(t, mk.GetDefault("a", 0), 1)
+	})
+
+	t.Run("KeepLast", func(t *testing.T) {
+		mk, err := orderedmap.MapKeys(m, func(k string, _ int) string { return strings.ToLower(k) }, orderedmap.CollisionKeepLast)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, mk.Keys(), []string{"a", "b", "c"})
+		gotwant.Test(t, mk.GetDefault("a", 0), 3)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := orderedmap.MapKeys(m, func(k string, _ int) string { return strings.ToLower(k) }, orderedmap.CollisionError)
+		gotwant.TestError(t, err, orderedmap.ErrKeyCollision)
+
+		mk, err := orderedmap.MapKeys(m, func(k string, v int) int { return v * 10 }, orderedmap.CollisionError)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, mk.Keys(), []int{10, 20, 30, 40})
+	})
+}
+
+func TestReduce(t *testing.T) {
+	m := newFuncsMap()
+
+	s := orderedmap.Reduce(m, "", func(acc string, k string, v int) string {
+		return acc + k
+	})
+	gotwant.Test(t, s, "zbxa")
+
+	sum := orderedmap.Reduce(m, 0, func(acc int, k string, v int) int {
+		return acc + v
+	})
+	gotwant.Test(t, sum, 10)
+}
+
+func TestPartition(t *testing.T) {
+	m := newFuncsMap()
+
+	even, odd := orderedmap.Partition(m, func(k string, v int) bool {
+		return v%2 == 0
+	})
+	gotwant.Test(t, even.Keys(), []string{"b", "a"})
+	gotwant.Test(t, odd.Keys(), []string{"z", "x"})
+}
+
+func TestGroupBy(t *testing.T) {
+	m := newFuncsMap()
+	m.Set("y", 5)
+
+	g := orderedmap.GroupBy(m, func(k string, v int) bool {
+		return v%2 == 0
+	})
+	gotwant.Test(t, g.Keys(), []bool{false, true})
+	gotwant.Test(t, g.GetDefault(false, nil).Keys(), []string{"z", "x", "y"})
+	gotwant.Test(t, g.GetDefault(true, nil).Keys(), []string{"b", "a"})
+}
+
+func TestDeleteFunc(t *testing.T) {
+	m := newFuncsMap()
+
+	orderedmap.DeleteFunc(m, func(k string, v int) bool {
+		return v%2 == 0
+	})
+	gotwant.Test(t, m.Keys(), []string{"z", "x"})
+	gotwant.Test(t, m.Len(), 2)
+	gotwant.Test(t, m.Contains("b"), false)
+
+	// still consistent
+	m.Delete("x")
+	m.Set("b", 2)
+	gotwant.Test(t, m.Keys(), []string{"z", "b"})
+
+	m.PreserveOrder(false)
+	m.Set("z", 10)
+	gotwant.Test(t, m.Keys(), []string{"b", "z"})
+}
@@ -0,0 +1,305 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HashedMap is an ordered map whose keys are not necessarily comparable.
+// Keys are identified by the hash and equality functions given to NewHashed,
+// so a HashedMap must be made by NewHashed.
+type HashedMap[K, V any] struct {
+	hash func(K) uint64
+	eq   func(a, b K) bool
+
+	// ids of entries by hash
+	buckets map[uint64][]uint64
+	// entries by id, in order
+	entries *OrderedMap[uint64, hashedEntry[K, V]]
+
+	nextID uint64
+}
+
+// Entry requires a comparable K.
+type hashedEntry[K, V any] struct {
+	key   K
+	value V
+}
+
+func NewHashed[K, V any](hash func(K) uint64, eq func(a, b K) bool) *HashedMap[K, V] {
+	return &HashedMap[K, V]{
+		hash:    hash,
+		eq:      eq,
+		buckets: make(map[uint64][]uint64),
+		entries: New[uint64, hashedEntry[K, V]](),
+	}
+}
+
+func (m *HashedMap[K, V]) PreserveOrder(b bool) {
+	m.entries.PreserveOrder(b)
+}
+
+func (m *HashedMap[K, V]) Set(key K, value V) {
+	if m == nil {
+		panic("assignment to entry in nil map")
+	}
+
+	h := m.hash(key)
+	if id, e, found := m.find(h, key); found {
+		e.value = value
+		m.entries.Set(id, e)
+		return
+	}
+
+	id := m.nextID
+	m.nextID++
+	m.buckets[h] = append(m.buckets[h], id)
+	m.entries.Set(id, hashedEntry[K, V]{key: key, value: value})
+}
+
+func (m *HashedMap[K, V]) Delete(key K) {
+	if m == nil {
+		return
+	}
+
+	h := m.hash(key)
+	bucket := m.buckets[h]
+	for i, id := range bucket {
+		e, _ := m.entries.Get(id)
+		if !m.eq(e.key, key) {
+			continue
+		}
+
+		if len(bucket) == 1 {
+			delete(m.buckets, h)
+		} else {
+			m.buckets[h] = append(bucket[:i:i], bucket[i+1:]...)
+		}
+		m.entries.Delete(id)
+		return
+	}
+}
+
+func (m *HashedMap[K, V]) Get(key K) (V, bool) {
+	if m == nil {
+		var gnil V
+		return gnil, false
+	}
+
+	_, e, found := m.find(m.hash(key), key)
+	return e.value, found
+}
+
+func (m *HashedMap[K, V]) GetDefault(key K, defvalue V) V {
+	if value, found := m.Get(key); found {
+		return value
+	}
+	return defvalue
+}
+
+func (m *HashedMap[K, V]) Contains(key K) bool {
+	_, found := m.Get(key)
+	return found
+}
+
+func (m *HashedMap[K, V]) Len() int {
+	if m == nil {
+		return 0
+	}
+	return m.entries.Len()
+}
+
+// Keys returns a copy of the keys in order.
+func (m *HashedMap[K, V]) Keys() []K {
+	if m == nil {
+		return nil
+	}
+
+	var keys []K
+	for _, e := range m.entries.Values() {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Values returns the values in order.
+func (m *HashedMap[K, V]) Values() []V {
+	if m == nil {
+		return nil
+	}
+
+	var values []V
+	for _, e := range m.entries.Values() {
+		values = append(values, e.value)
+	}
+	return values
+}
+
+// IndexOf returns the position of key, or -1 if key is not found.
+func (m *HashedMap[K, V]) IndexOf(key K) int {
+	if m == nil {
+		return -1
+	}
+
+	id, _, found := m.find(m.hash(key), key)
+	if !found {
+		return -1
+	}
+	return m.entries.IndexOf(id)
+}
+
+// At returns the key and the value at the position i.
+func (m *HashedMap[K, V]) At(i int) (K, V, bool) {
+	if m == nil {
+		var gnilk K
+		var gnilv V
+		return gnilk, gnilv, false
+	}
+
+	_, e, found := m.entries.At(i)
+	return e.key, e.value, found
+}
+
+// MoveTo moves key to the position i.
+func (m *HashedMap[K, V]) MoveTo(key K, i int) bool {
+	if m == nil {
+		return false
+	}
+
+	id, _, found := m.find(m.hash(key), key)
+	if !found {
+		return false
+	}
+	return m.entries.MoveTo(id, i)
+}
+
+// MarshalJSON encodes keys by encoding.TextMarshaler, or as strings.
+func (m *HashedMap[K, V]) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, e := range m.entries.Values() {
+		text, err := marshalKeyText(e.key)
+		if err != nil {
+			return nil, err
+		}
+		k, err := json.Marshal(text)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes keys by encoding.TextUnmarshaler, or as strings and numbers.
+func (m *HashedMap[K, V]) UnmarshalJSON(b []byte) error {
+	raw := New[string, json.RawMessage]()
+	if err := raw.UnmarshalJSON(b); err != nil {
+		return err
+	}
+
+	return fromRawEntries(m, raw.Entries(), func(r json.RawMessage, v *V) error {
+		return json.Unmarshal(r, v)
+	})
+}
+
+// MarshalYAML encodes keys by encoding.TextMarshaler.
+func (m *HashedMap[K, V]) MarshalYAML() (any, error) {
+	if m == nil || m.Len() == 0 {
+		return nil, nil
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, e := range m.entries.Values() {
+		text, err := marshalKeyText(e.key)
+		if err != nil {
+			return nil, err
+		}
+
+		var knode, vnode yaml.Node
+		if err := knode.Encode(text); err != nil {
+			return nil, err
+		}
+		if err := vnode.Encode(e.value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &knode, &vnode)
+	}
+	return node, nil
+}
+
+// UnmarshalYAML decodes keys by encoding.TextUnmarshaler, or as strings and numbers.
+func (m *HashedMap[K, V]) UnmarshalYAML(value *yaml.Node) error {
+	var pairs []Entry[string, *yaml.Node]
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		pairs = append(pairs, Entry[string, *yaml.Node]{Key: value.Content[i].Value, Value: value.Content[i+1]})
+	}
+
+	return fromRawEntries(m, pairs, func(n *yaml.Node, v *V) error {
+		return n.Decode(v)
+	})
+}
+
+func (m *HashedMap[K, V]) find(h uint64, key K) (uint64, hashedEntry[K, V], bool) {
+	for _, id := range m.buckets[h] {
+		e, _ := m.entries.Get(id)
+		if m.eq(e.key, key) {
+			return id, e, true
+		}
+	}
+	return 0, hashedEntry[K, V]{}, false
+}
+
+func fromRawEntries[K, V, R any](m *HashedMap[K, V], pairs []Entry[string, R], decode func(R, *V) error) error {
+	if m.entries == nil {
+		return errors.New("orderedmap: HashedMap must be made by NewHashed")
+	}
+
+	m.buckets = make(map[uint64][]uint64)
+	m.entries = New[uint64, hashedEntry[K, V]](WithPreserveOrder(!m.entries.overwriteSeq))
+
+	for _, p := range pairs {
+		k, err := parseText[K](p.Key)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", p.Key, err)
+		}
+
+		var v V
+		if err := decode(p.Value, &v); err != nil {
+			return err
+		}
+		m.Set(k, v)
+	}
+	return nil
+}
+
+func marshalKeyText(key any) (string, error) {
+	switch k := key.(type) {
+	case encoding.TextMarshaler:
+		b, err := k.MarshalText()
+		return string(b), err
+	case string:
+		return k, nil
+	}
+	return "", fmt.Errorf("key type %T does not implement encoding.TextMarshaler", key)
+}
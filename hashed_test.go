@@ -0,0 +1,169 @@
+package orderedmap_test
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+type pathKey []string
+
+func (p pathKey) MarshalText() ([]byte, error) {
+	return []byte(strings.Join(p, "/")), nil
+}
+
+func (p *pathKey) UnmarshalText(b []byte) error {
+	*p = strings.Split(string(b), "/")
+	return nil
+}
+
+func hashPath(p pathKey) uint64 {
+	h := fnv.New64a()
+	for _, s := range p {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func eqPath(a, b pathKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHashed(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		hash func(pathKey) uint64
+	}{
+		{"FNV", hashPath},
+		{"Collision", func(pathKey) uint64 { return 0 }},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			m := orderedmap.NewHashed[pathKey, int](c.hash, eqPath)
+			m.Set(pathKey{"a", "b"}, 1)
+			m.Set(pathKey{"a"}, 2)
+			m.Set(pathKey{"c"}, 3)
+			m.Set(pathKey{"a", "b"}, 10)
+
+			gotwant.Test(t, m.Len(), 3)
+			gotwant.Test(t, m.Keys(), []pathKey{{"a", "b"}, {"a"}, {"c"}})
+			gotwant.Test(t, m.Values(), []int{10, 2, 3})
+			gotwant.Test(t, m.GetDefault(pathKey{"a", "b"}, 0), 10)
+			gotwant.Test(t, m.Contains(pathKey{"b"}), false)
+			gotwant.Test(t, m.IndexOf(pathKey{"c"}), 2)
+
+			m.Delete(pathKey{"a"})
+			gotwant.Test(t, m.Len(), 2)
+			gotwant.Test(t, m.Contains(pathKey{"a"}), false)
+			gotwant.Test(t, m.Contains(pathKey{"c"}), true)
+			gotwant.Test(t, m.IndexOf(pathKey{"c"}), 1)
+
+			m.Set(pathKey{"a"}, 20)
+			k, v, found := m.At(2)
+			gotwant.Test(t, found, true)
+			gotwant.Test(t, k, pathKey{"a"})
+			gotwant.Test(t, v, 20)
+
+			gotwant.Test(t, m.MoveTo(pathKey{"a"}, 0), true)
+			gotwant.Test(t, m.Keys(), []pathKey{{"a"}, {"a", "b"}, {"c"}})
+
+			m.PreserveOrder(false)
+			m.Set(pathKey{"a"}, 30)
+			gotwant.Test(t, m.Keys(), []pathKey{{"a", "b"}, {"c"}, {"a"}})
+		})
+	}
+}
+
+func TestHashedJSON(t *testing.T) {
+	m := orderedmap.NewHashed[pathKey, int](hashPath, eqPath)
+	m.Set(pathKey{"z", "y"}, 1)
+	m.Set(pathKey{"a"}, 2)
+
+	b, err := json.Marshal(m)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b), `{"z/y":1,"a":2}`)
+
+	m2 := orderedmap.NewHashed[pathKey, int](func(pathKey) uint64 { return 0 }, eqPath)
+	err = json.Unmarshal([]byte(`{"q/r":3,"z/y":1,"a":2}`), m2)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m2.Keys(), []pathKey{{"q", "r"}, {"z", "y"}, {"a"}})
+	gotwant.Test(t, m2.GetDefault(pathKey{"z", "y"}, 0), 1)
+
+	bad := orderedmap.NewHashed[[]int, int](func([]int) uint64 { return 0 }, func(a, b []int) bool { return len(a) == len(b) })
+	bad.Set([]int{1}, 1)
+	_, err = json.Marshal(bad)
+	gotwant.TestError(t, err, "does not implement encoding.TextMarshaler")
+	err = json.Unmarshal([]byte(`{"1":1}`), bad)
+	gotwant.TestError(t, err, `key "1": unsupported type []int`)
+
+	t.Run("EscapedKey", func(t *testing.T) {
+		m := orderedmap.NewHashed[pathKey, int](hashPath, eqPath)
+		m.Set(pathKey{`a"b`, `c\d`}, 1)
+
+		b, err := json.Marshal(m)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, string(b), `{"a\"b/c\\d":1}`)
+
+		m2 := orderedmap.NewHashed[pathKey, int](hashPath, eqPath)
+		gotwant.TestError(t, json.Unmarshal(b, m2), nil)
+		gotwant.Test(t, m2.Keys(), []pathKey{{`a"b`, `c\d`}})
+	})
+
+	t.Run("StringKey", func(t *testing.T) {
+		m := orderedmap.NewHashed[string, int](hashString, func(a, b string) bool { return a == b })
+		m.Set("b", 1)
+		m.Set("a", 2)
+
+		b, err := json.Marshal(m)
+		gotwant.TestError(t, err, nil)
+
+		m2 := orderedmap.NewHashed[string, int](hashString, func(a, b string) bool { return a == b })
+		gotwant.TestError(t, json.Unmarshal(b, m2), nil)
+		gotwant.Test(t, m2.Keys(), []string{"b", "a"})
+		gotwant.Test(t, m2.GetDefault("a", 0), 2)
+	})
+
+	t.Run("ZeroValue", func(t *testing.T) {
+		var s struct {
+			M orderedmap.HashedMap[string, int]
+		}
+		err := json.Unmarshal([]byte(`{"M":{"a":1}}`), &s)
+		gotwant.TestError(t, err, "HashedMap must be made by NewHashed")
+	})
+}
+
+func TestHashedYAML(t *testing.T) {
+	m := orderedmap.NewHashed[pathKey, string](hashPath, eqPath)
+	m.Set(pathKey{"z", "y"}, "one")
+	m.Set(pathKey{"a"}, "two")
+
+	b, err := yaml.Marshal(m)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b), "z/y: one\na: two\n")
+
+	m2 := orderedmap.NewHashed[pathKey, string](hashPath, eqPath)
+	err = yaml.Unmarshal(b, m2)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m2.Keys(), []pathKey{{"z", "y"}, {"a"}})
+	gotwant.Test(t, m2.GetDefault(pathKey{"a"}, ""), "two")
+}
@@ -0,0 +1,197 @@
+package orderedmap
+
+// WithHistory enables Undo and Redo, keeping up to limit steps. (0 for unlimited)
+//
+// A step is a call of Set, Delete, MoveTo, Sort, DeleteFunc, UnmarshalJSON, ...,
+// or a committed Tx.
+func WithHistory(limit int) Option {
+	return func(o *options) {
+		o.history = true
+		o.historyLimit = limit
+	}
+}
+
+type history[K comparable, V any] struct {
+	limit int
+
+	undo, redo []step[K, V]
+
+	// nesting of beginStep
+	depth   int
+	current []change[K, V]
+}
+
+// step is a group of changes, or a checkpoint if label is set.
+type step[K comparable, V any] struct {
+	changes []change[K, V]
+	label   string
+}
+
+func (s step[K, V]) isCheckpoint() bool {
+	return s.changes == nil
+}
+
+// Undo cancels the last step.
+// It returns false if there is nothing to undo, history is not enabled or m is in Tx.
+func (m *OrderedMap[K, V]) Undo() bool {
+	if !m.CanUndo() {
+		return false
+	}
+
+	h := m.history
+	for h.undo[len(h.undo)-1].isCheckpoint() {
+		h.moveTop(&h.undo, &h.redo)
+	}
+	s := h.moveTop(&h.undo, &h.redo)
+
+	for i := len(s.changes) - 1; i >= 0; i-- {
+		m.replay(s.changes[i].inverse())
+	}
+	return true
+}
+
+// Redo makes the last undone step again.
+func (m *OrderedMap[K, V]) Redo() bool {
+	if !m.CanRedo() {
+		return false
+	}
+
+	h := m.history
+	s := h.moveTop(&h.redo, &h.undo)
+	for _, c := range s.changes {
+		m.replay(c)
+	}
+
+	for len(h.redo) > 0 && h.redo[len(h.redo)-1].isCheckpoint() {
+		h.moveTop(&h.redo, &h.undo)
+	}
+	return true
+}
+
+// CanUndo reports whether Undo does something.
+func (m *OrderedMap[K, V]) CanUndo() bool {
+	if m == nil || m.history == nil || m.intx {
+		return false
+	}
+
+	for _, s := range m.history.undo {
+		if !s.isCheckpoint() {
+			return true
+		}
+	}
+	return false
+}
+
+// CanRedo reports whether Redo does something.
+func (m *OrderedMap[K, V]) CanRedo() bool {
+	return m != nil && m.history != nil && !m.intx && len(m.history.redo) > 0
+}
+
+// Checkpoint marks the current state with label.
+func (m *OrderedMap[K, V]) Checkpoint(label string) {
+	if m == nil || m.history == nil {
+		return
+	}
+
+	m.history.undo = append(m.history.undo, step[K, V]{label: label})
+}
+
+// UndoTo cancels the steps after the last checkpoint of label.
+// It returns false if the checkpoint is not found.
+func (m *OrderedMap[K, V]) UndoTo(label string) bool {
+	if m == nil || m.history == nil || m.intx {
+		return false
+	}
+
+	h := m.history
+	idx := -1
+	for i := len(h.undo) - 1; i >= 0; i-- {
+		if h.undo[i].isCheckpoint() && h.undo[i].label == label {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return false
+	}
+
+	for len(h.undo) > idx+1 {
+		if h.undo[len(h.undo)-1].isCheckpoint() {
+			// not by Undo, which would go on below idx
+			h.moveTop(&h.undo, &h.redo)
+			continue
+		}
+		m.Undo()
+	}
+	return true
+}
+
+// replay applies c and notifies observers without recording it.
+func (m *OrderedMap[K, V]) replay(c change[K, V]) {
+	m.apply(c)
+
+	for _, o := range m.observers {
+		o.fn(c.Event)
+	}
+}
+
+// beginStep starts grouping changes into a step, until the pairing endStep.
+func (m *OrderedMap[K, V]) beginStep() {
+	if m.history != nil {
+		m.history.depth++
+	}
+}
+
+func (m *OrderedMap[K, V]) endStep() {
+	if m.history == nil {
+		return
+	}
+
+	h := m.history
+	h.depth--
+	if h.depth == 0 && len(h.current) > 0 {
+		h.push(step[K, V]{changes: h.current})
+		h.current = nil
+	}
+}
+
+func (h *history[K, V]) record(c change[K, V]) {
+	if h.depth > 0 {
+		h.current = append(h.current, c)
+		return
+	}
+	h.push(step[K, V]{changes: []change[K, V]{c}})
+}
+
+func (h *history[K, V]) push(s step[K, V]) {
+	h.undo = append(h.undo, s)
+	h.redo = nil
+
+	if h.limit <= 0 {
+		return
+	}
+
+	count := 0
+	for _, s := range h.undo {
+		if !s.isCheckpoint() {
+			count++
+		}
+	}
+	drop := 0
+	for ; count > h.limit; drop++ {
+		if !h.undo[drop].isCheckpoint() {
+			count--
+		}
+	}
+	if drop > 0 {
+		h.undo = append(h.undo[:0:0], h.undo[drop:]...)
+	}
+}
+
+// moveTop moves the top of from to to, and returns it.
+func (h *history[K, V]) moveTop(from, to *[]step[K, V]) step[K, V] {
+	s := (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+	*to = append(*to, s)
+	return s
+}
@@ -0,0 +1,208 @@
+package orderedmap_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestHistory(t *testing.T) {
+	m := orderedmap.New[string, int](orderedmap.WithHistory(0))
+	gotwant.Test(t, m.Undo(), false)
+	gotwant.Test(t, m.Redo(), false)
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+	m.Set("a", 10)
+	m.Delete("b")
+	m.MoveToFront("c")
+	m.Sort(func(a, b string) bool { return a > b })
+
+	states := [][]string{
+		{"c", "a"},
+		{"c", "a"},
+		{"a", "c"},
+		{"a", "b", "c"},
+		{"a", "b", "c"},
+		{"a", "b"},
+		{"a"},
+		nil,
+	}
+	values := [][]int{
+		{3, 10},
+		{3, 10},
+		{10, 3},
+		{10, 2, 3},
+		{1, 2, 3},
+		{1, 2},
+		{1},
+		nil,
+	}
+
+	for i := 1; i < len(states); i++ {
+		gotwant.Test(t, m.Undo(), true)
+		gotwant.Test(t, append([]string(nil), m.Keys()...), states[i])
+		gotwant.Test(t, append([]int(nil), m.Values()...), values[i])
+	}
+	gotwant.Test(t, m.Undo(), false)
+
+	for i := len(states) - 2; i >= 0; i-- {
+		gotwant.Test(t, m.Redo(), true)
+		gotwant.Test(t, m.Keys(), states[i])
+		gotwant.Test(t, m.Values(), values[i])
+	}
+	gotwant.Test(t, m.Redo(), false)
+
+	// a new change clears redo
+	m.Undo()
+	m.Set("z", 0)
+	gotwant.Test(t, m.CanRedo(), false)
+	gotwant.Test(t, m.Keys(), []string{"c", "a", "z"})
+}
+
+func TestHistoryCheckpoint(t *testing.T) {
+	m := orderedmap.New[string, int](orderedmap.WithHistory(0))
+	m.Set("a", 1)
+	m.Checkpoint("one")
+	m.Set("b", 2)
+	m.Set("c", 3)
+	m.Checkpoint("two")
+	m.Set("d", 4)
+
+	gotwant.Test(t, m.UndoTo("two"), true)
+	gotwant.Test(t, m.Keys(), []string{"a", "b", "c"})
+	gotwant.Test(t, m.UndoTo("one"), true)
+	gotwant.Test(t, m.Keys(), []string{"a"})
+	gotwant.Test(t, m.UndoTo("none"), false)
+
+	m.Redo()
+	m.Redo()
+	m.Redo()
+	gotwant.Test(t, m.Keys(), []string{"a", "b", "c", "d"})
+	gotwant.Test(t, m.UndoTo("two"), true)
+	gotwant.Test(t, m.Keys(), []string{"a", "b", "c"})
+
+	t.Run("Stacked", func(t *testing.T) {
+		m := orderedmap.New[string, int](orderedmap.WithHistory(0))
+		m.Set("a", 1)
+		m.Checkpoint("A")
+		m.Checkpoint("B")
+
+		gotwant.Test(t, m.UndoTo("A"), true)
+		gotwant.Test(t, m.Keys(), []string{"a"})
+		gotwant.Test(t, m.UndoTo("B"), false)
+
+		m.Set("b", 2)
+		m.Checkpoint("C")
+		gotwant.Test(t, m.UndoTo("A"), true)
+		gotwant.Test(t, m.Keys(), []string{"a"})
+		gotwant.Test(t, m.CanUndo(), true)
+	})
+}
+
+func TestHistoryLimit(t *testing.T) {
+	m := orderedmap.New[int, int](orderedmap.WithHistory(3))
+	for i := 0; i < 10; i++ {
+		m.Set(i, i)
+	}
+
+	for m.Undo() {
+	}
+	gotwant.Test(t, m.Keys(), []int{0, 1, 2, 3, 4, 5, 6})
+}
+
+func TestHistoryGroups(t *testing.T) {
+	m := orderedmap.New[int, int](orderedmap.WithHistory(0))
+	for i := 0; i < 5; i++ {
+		m.Set(i, i)
+	}
+
+	orderedmap.DeleteFunc(m, func(k, v int) bool { return k%2 == 0 })
+	gotwant.Test(t, m.Keys(), []int{1, 3})
+	m.Undo()
+	gotwant.Test(t, m.Keys(), []int{0, 1, 2, 3, 4})
+
+	m.Tx(func(tx *orderedmap.Tx[int, int]) error {
+		tx.Delete(0)
+		tx.Set(9, 9)
+		tx.Set(1, 10)
+		return nil
+	})
+	m.Tx(func(tx *orderedmap.Tx[int, int]) error {
+		tx.Delete(1)
+		gotwant.Test(t, m.CanUndo(), false)
+		return errors.New("failed")
+	})
+	gotwant.Test(t, m.Keys(), []int{1, 2, 3, 4, 9})
+	m.Undo()
+	gotwant.Test(t, m.Keys(), []int{0, 1, 2, 3, 4})
+	gotwant.Test(t, m.Values(), []int{0, 1, 2, 3, 4})
+
+	json.Unmarshal([]byte(`{"9":1}`), m)
+	gotwant.Test(t, m.Keys(), []int{9})
+	m.Undo()
+	gotwant.Test(t, m.Keys(), []int{0, 1, 2, 3, 4})
+	m.Redo()
+	gotwant.Test(t, m.Keys(), []int{9})
+
+	m.Clear()
+	gotwant.Test(t, m.Len(), 0)
+	m.Undo()
+	gotwant.Test(t, m.Keys(), []int{9})
+}
+
+func TestHistoryEvents(t *testing.T) {
+	m := orderedmap.New[string, int](orderedmap.WithHistory(0))
+
+	var kinds []orderedmap.EventKind
+	m.OnChange(func(ev orderedmap.Event[string, int]) {
+		kinds = append(kinds, ev.Kind)
+	})
+
+	m.Set("a", 1)
+	m.Undo()
+	m.Redo()
+	gotwant.Test(t, kinds, []orderedmap.EventKind{orderedmap.EventInsert, orderedmap.EventDelete, orderedmap.EventInsert})
+}
+
+func TestHistoryRandom(t *testing.T) {
+	m := orderedmap.New[int, int](orderedmap.WithHistory(0))
+
+	var keys [][]int
+	var values [][]int
+	for i := 0; i < 300; i++ {
+		keys = append(keys, m.Keys())
+		values = append(values, m.Values())
+
+		k := rand.Intn(30)
+		switch rand.Intn(5) {
+		case 0, 1:
+			m.Set(k, rand.Int())
+		case 2:
+			m.Delete(k)
+			if !m.CanUndo() || len(keys[len(keys)-1]) == m.Len() {
+				// no change, no step
+				keys = keys[:len(keys)-1]
+				values = values[:len(values)-1]
+			}
+		case 3:
+			if !m.MoveTo(k, rand.Intn(m.Len()+1)) {
+				keys = keys[:len(keys)-1]
+				values = values[:len(values)-1]
+			}
+		case 4:
+			m.Reverse()
+		}
+	}
+
+	for i := len(keys) - 1; i >= 0; i-- {
+		gotwant.Test(t, m.Undo(), true)
+		gotwant.Test(t, append([]int{}, m.Keys()...), append([]int{}, keys[i]...))
+		gotwant.Test(t, append([]int{}, m.Values()...), append([]int{}, values[i]...))
+	}
+}
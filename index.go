@@ -0,0 +1,306 @@
+package orderedmap
+
+import "math/rand"
+
+// IndexOf returns the position of key, or -1 if key is not found.
+func (m *OrderedMap[K, V]) IndexOf(key K) int {
+	if m == nil {
+		return -1
+	}
+
+	e, found := m.m[m.mapKey(key)]
+	if !found {
+		return -1
+	}
+	return e.index()
+}
+
+// At returns the key and the value at the position i.
+func (m *OrderedMap[K, V]) At(i int) (K, V, bool) {
+	if m == nil || i < 0 || len(m.m) <= i {
+		var gnilk K
+		var gnilv V
+		return gnilk, gnilv, false
+	}
+
+	e := m.at(i)
+	return e.key, e.v, true
+}
+
+// MoveTo moves key to the position i.
+// It returns false if key is not found, i is out of range or m is sorted.
+func (m *OrderedMap[K, V]) MoveTo(key K, i int) bool {
+	if m == nil || m.cmp != nil || i < 0 || len(m.m) <= i {
+		return false
+	}
+
+	e, found := m.m[m.mapKey(key)]
+	if !found {
+		return false
+	}
+
+	var from int
+	if m.tracking() {
+		from = e.index()
+	}
+
+	m.unlink(e)
+	m.insertAt(i, e)
+
+	if m.tracking() {
+		m.changed(change[K, V]{
+			Event: Event[K, V]{
+				Kind:     EventMove,
+				Key:      e.key,
+				OldValue: e.v,
+				NewValue: e.v,
+				OldIndex: from,
+				NewIndex: i,
+			},
+			e: e,
+		})
+	}
+	return true
+}
+
+// MoveToFront moves key to the first position.
+func (m *OrderedMap[K, V]) MoveToFront(key K) bool {
+	return m.MoveTo(key, 0)
+}
+
+// MoveToBack moves key to the last position.
+func (m *OrderedMap[K, V]) MoveToBack(key K) bool {
+	return m.MoveTo(key, m.Len()-1)
+}
+
+// The entries are kept in order as an implicit treap:
+// a binary tree ordered by position, balanced by random priorities (a max-heap),
+// where each node counts the entries of its subtree.
+// Inserting, removing and finding the position of an entry are O(log n) expected.
+
+// first returns the first entry, or nil.
+func (m *OrderedMap[K, V]) first() *elem[K, V] {
+	e := m.root
+	if e == nil {
+		return nil
+	}
+	for e.left != nil {
+		e = e.left
+	}
+	return e
+}
+
+// next returns the entry after e, or nil.
+func (e *elem[K, V]) next() *elem[K, V] {
+	if e.right != nil {
+		e = e.right
+		for e.left != nil {
+			e = e.left
+		}
+		return e
+	}
+
+	for e.parent != nil && e.parent.right == e {
+		e = e.parent
+	}
+	return e.parent
+}
+
+// index returns the position of e.
+func (e *elem[K, V]) index() int {
+	i := e.left.count()
+	for ; e.parent != nil; e = e.parent {
+		if e.parent.right == e {
+			i += e.parent.left.count() + 1
+		}
+	}
+	return i
+}
+
+// count returns the number of entries in the subtree of e.
+func (e *elem[K, V]) count() int {
+	if e == nil {
+		return 0
+	}
+	return e.size
+}
+
+func (e *elem[K, V]) recount() {
+	e.size = e.left.count() + e.right.count() + 1
+}
+
+// at returns the entry at the position i, which must be in range.
+func (m *OrderedMap[K, V]) at(i int) *elem[K, V] {
+	e := m.root
+	for {
+		n := e.left.count()
+		switch {
+		case i < n:
+			e = e.left
+		case i == n:
+			return e
+		default:
+			i -= n + 1
+			e = e.right
+		}
+	}
+}
+
+// push appends e to the end.
+func (m *OrderedMap[K, V]) push(e *elem[K, V]) {
+	m.insertAt(m.root.count(), e)
+}
+
+// insertAt places e at the position i, shifting the entries from i.
+func (m *OrderedMap[K, V]) insertAt(i int, e *elem[K, V]) {
+	e.parent, e.left, e.right = nil, nil, nil
+	e.size = 1
+	e.prio = m.nextPrio()
+
+	if m.root == nil {
+		m.root = e
+		return
+	}
+
+	// as a leaf
+	p := m.root
+	for {
+		p.size++
+		if i <= p.left.count() {
+			if p.left == nil {
+				p.left = e
+				break
+			}
+			p = p.left
+		} else {
+			i -= p.left.count() + 1
+			if p.right == nil {
+				p.right = e
+				break
+			}
+			p = p.right
+		}
+	}
+	e.parent = p
+
+	for e.parent != nil && e.prio > e.parent.prio {
+		m.rotateUp(e)
+	}
+}
+
+// unlink removes e from the order.
+func (m *OrderedMap[K, V]) unlink(e *elem[K, V]) {
+	// down to a leaf
+	for e.left != nil || e.right != nil {
+		c := e.left
+		if c == nil || e.right != nil && e.right.prio > c.prio {
+			c = e.right
+		}
+		m.rotateUp(c)
+	}
+
+	p := e.parent
+	switch {
+	case p == nil:
+		m.root = nil
+	case p.left == e:
+		p.left = nil
+	default:
+		p.right = nil
+	}
+	for ; p != nil; p = p.parent {
+		p.size--
+	}
+	e.parent = nil
+}
+
+// rotateUp replaces the parent of e with e, keeping the order.
+func (m *OrderedMap[K, V]) rotateUp(e *elem[K, V]) {
+	p := e.parent
+	g := p.parent
+
+	if p.left == e {
+		p.left = e.right
+		if e.right != nil {
+			e.right.parent = p
+		}
+		e.right = p
+	} else {
+		p.right = e.left
+		if e.left != nil {
+			e.left.parent = p
+		}
+		e.left = p
+	}
+	p.parent = e
+	p.recount()
+	e.recount()
+
+	e.parent = g
+	switch {
+	case g == nil:
+		m.root = e
+	case g.left == p:
+		g.left = e
+	default:
+		g.right = e
+	}
+}
+
+// rebuild makes the tree of the entries in order, in O(n).
+func (m *OrderedMap[K, V]) rebuild(order []*elem[K, V]) {
+	m.root = nil
+
+	// the right spine, as a Cartesian tree by priority
+	var spine []*elem[K, V]
+	for _, e := range order {
+		e.parent, e.left, e.right = nil, nil, nil
+		if e.prio == 0 {
+			e.prio = m.nextPrio()
+		}
+
+		var last *elem[K, V]
+		for len(spine) > 0 && spine[len(spine)-1].prio < e.prio {
+			last = spine[len(spine)-1]
+			last.recount()
+			spine = spine[:len(spine)-1]
+		}
+		if last != nil {
+			e.left = last
+			last.parent = e
+		}
+		if len(spine) > 0 {
+			top := spine[len(spine)-1]
+			top.right = e
+			e.parent = top
+		}
+		spine = append(spine, e)
+	}
+
+	for i := len(spine) - 1; i >= 0; i-- {
+		spine[i].recount()
+	}
+	if len(spine) > 0 {
+		m.root = spine[0]
+	}
+}
+
+// entries returns the entries in order.
+func (m *OrderedMap[K, V]) entries() []*elem[K, V] {
+	order := make([]*elem[K, V], 0, len(m.m))
+	for e := m.first(); e != nil; e = e.next() {
+		order = append(order, e)
+	}
+	return order
+}
+
+// nextPrio returns a random priority, never 0 (xorshift).
+func (m *OrderedMap[K, V]) nextPrio() uint64 {
+	if m.seed == 0 {
+		m.seed = rand.Uint64() | 1
+	}
+	m.seed ^= m.seed << 13
+	m.seed ^= m.seed >> 7
+	m.seed ^= m.seed << 17
+	return m.seed
+}
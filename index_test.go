@@ -0,0 +1,142 @@
+package orderedmap_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestIndexOf(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	gotwant.Test(t, m.IndexOf("a"), -1)
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+	m.Set("d", 4)
+	gotwant.Test(t, m.IndexOf("a"), 0)
+	gotwant.Test(t, m.IndexOf("c"), 2)
+
+	m.Delete("b")
+	gotwant.Test(t, m.IndexOf("a"), 0)
+	gotwant.Test(t, m.IndexOf("b"), -1)
+	gotwant.Test(t, m.IndexOf("c"), 1)
+	gotwant.Test(t, m.IndexOf("d"), 2)
+
+	m.PreserveOrder(false)
+	m.Set("a", 10)
+	gotwant.Test(t, m.IndexOf("a"), 2)
+	gotwant.Test(t, m.IndexOf("c"), 0)
+}
+
+func TestAt(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	_, _, found := m.At(0)
+	gotwant.Test(t, found, false)
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+	m.Delete("a")
+
+	k, v, found := m.At(0)
+	gotwant.Test(t, found, true)
+	gotwant.Test(t, k, "b")
+	gotwant.Test(t, v, 2)
+
+	k, v, found = m.At(1)
+	gotwant.Test(t, found, true)
+	gotwant.Test(t, k, "c")
+	gotwant.Test(t, v, 3)
+
+	_, _, found = m.At(2)
+	gotwant.Test(t, found, false)
+	_, _, found = m.At(-1)
+	gotwant.Test(t, found, false)
+}
+
+func TestMove(t *testing.T) {
+	m := orderedmap.New[int, int]()
+	for i := 0; i < 5; i++ {
+		m.Set(i, i)
+	}
+
+	gotwant.Test(t, m.MoveToFront(3), true)
+	gotwant.Test(t, m.Keys(), []int{3, 0, 1, 2, 4})
+
+	gotwant.Test(t, m.MoveToBack(0), true)
+	gotwant.Test(t, m.Keys(), []int{3, 1, 2, 4, 0})
+
+	gotwant.Test(t, m.MoveTo(4, 1), true)
+	gotwant.Test(t, m.Keys(), []int{3, 4, 1, 2, 0})
+
+	gotwant.Test(t, m.MoveTo(3, 3), true)
+	gotwant.Test(t, m.Keys(), []int{4, 1, 2, 3, 0})
+
+	gotwant.Test(t, m.MoveTo(9, 0), false)
+	gotwant.Test(t, m.MoveTo(1, 5), false)
+	gotwant.Test(t, m.Keys(), []int{4, 1, 2, 3, 0})
+
+	s := orderedmap.NewSorted[int, int](intcmp)
+	s.Set(1, 1)
+	s.Set(2, 2)
+	gotwant.Test(t, s.MoveToFront(2), false)
+	gotwant.Test(t, s.Keys(), []int{1, 2})
+}
+
+func TestIndexRandomOperations(t *testing.T) {
+	m := orderedmap.New[int, int]()
+	var keys []int
+
+	indexOf := func(key int) int {
+		for i, k := range keys {
+			if k == key {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for i := 0; i < 5000; i++ {
+		key := rand.Intn(100)
+
+		switch rand.Intn(4) {
+		case 0, 1:
+			m.Set(key, key)
+			if indexOf(key) == -1 {
+				keys = append(keys, key)
+			}
+
+		case 2:
+			m.Delete(key)
+			if idx := indexOf(key); idx != -1 {
+				keys = append(keys[:idx], keys[idx+1:]...)
+			}
+
+		case 3:
+			if len(keys) == 0 {
+				continue
+			}
+			to := rand.Intn(len(keys))
+			moved := m.MoveTo(key, to)
+			idx := indexOf(key)
+			gotwant.Test(t, moved, idx != -1)
+			if idx != -1 {
+				keys = append(keys[:idx], keys[idx+1:]...)
+				keys = append(keys[:to], append([]int{key}, keys[to:]...)...)
+			}
+		}
+
+		gotwant.Test(t, m.Len(), len(keys))
+		gotwant.Test(t, m.IndexOf(key), indexOf(key))
+		if len(keys) > 0 {
+			pos := rand.Intn(len(keys))
+			k, _, _ := m.At(pos)
+			gotwant.Test(t, k, keys[pos])
+		}
+	}
+
+	gotwant.Test(t, append([]int{}, m.Keys()...), append([]int{}, keys...))
+}
@@ -0,0 +1,235 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MarshalINI encodes m as an INI file.
+// Nested maps are written as [sections] after the other keys, which come first in the file.
+func (m *OrderedMap[K, V]) MarshalINI() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if m == nil {
+		return buf.Bytes(), nil
+	}
+
+	type section struct {
+		lines []string
+		name  string
+		m     anyMap
+	}
+	var sections []section
+
+	err := m.eachNote(func(lines []string, key, value any) error {
+		k, err := formatKey(key)
+		if err != nil {
+			return err
+		}
+
+		p, err := plainValue(value)
+		if err != nil {
+			return err
+		}
+		if sub, ok := p.(anyMap); ok {
+			sections = append(sections, section{lines, k, sub})
+			return nil
+		}
+
+		writeLines(buf, lines)
+		return writeINIKeyValue(buf, k, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range sections {
+		writeLines(buf, s.lines)
+		buf.WriteString("[" + s.name + "]\n")
+
+		err := s.m.eachNote(func(lines []string, key, value any) error {
+			k, err := formatKey(key)
+			if err != nil {
+				return err
+			}
+			writeLines(buf, lines)
+			return writeINIKeyValue(buf, k, value)
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeLines(buf, s.m.trailerLines())
+	}
+
+	writeLines(buf, m.trailer)
+	return buf.Bytes(), nil
+}
+
+func writeINIKeyValue(buf *bytes.Buffer, key string, value any) error {
+	v, ok, err := scalarText(value)
+	if err != nil {
+		return err
+	}
+	if !ok && value != nil {
+		return fmt.Errorf("ini: unsupported type %T", value)
+	}
+	if strings.ContainsAny(key, "=[\n") || strings.ContainsAny(v, "\n") {
+		return fmt.Errorf("ini: can not write %q = %q", key, v)
+	}
+
+	if v != strings.TrimSpace(v) || strings.HasPrefix(v, `"`) {
+		v = `"` + v + `"`
+	}
+	buf.WriteString(key)
+	buf.WriteString(" = ")
+	buf.WriteString(v)
+	buf.WriteByte('\n')
+	return nil
+}
+
+// UnmarshalINI decodes an INI file into m.
+//
+// The keys before the first section are set to m,
+// and each [section] is set as a map: V itself if V is a *OrderedMap, *OrderedMap[string, string] if V is any,
+// otherwise converted to V through encoding/json.
+// Lines are key = value (or key: value), and comments begin with ; or #.
+// A value in double quotes is unquoted.
+//
+// m is left as it was if b is not valid.
+func (m *OrderedMap[K, V]) UnmarshalINI(b []byte) error {
+	s := m.blank()
+	if err := s.decodeINI(b); err != nil {
+		return err
+	}
+
+	defer m.beginDecode()()
+	m.m, m.root, m.trailer = s.m, s.root, s.trailer
+	return nil
+}
+
+func (m *OrderedMap[K, V]) decodeINI(b []byte) error {
+	var (
+		pending []string
+
+		section  textMap
+		sections []textMap
+		names    []K
+		direct   []bool
+	)
+
+	for i, raw := range textLines(b) {
+		lineno := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			pending = append(pending, raw)
+			continue
+		}
+
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return fmt.Errorf("ini: line %d: ] is required", lineno)
+			}
+			name, err := parseText[K](strings.TrimSpace(line[1 : len(line)-1]))
+			if err != nil {
+				return fmt.Errorf("ini: line %d: %w", lineno, err)
+			}
+
+			// a section appearing again is merged
+			section = nil
+			for j, n := range names {
+				if n == name {
+					section = sections[j]
+				}
+			}
+			if section == nil {
+				var v V
+				var ok bool
+				v, section, ok = m.newSection()
+				if !ok && !canHoldMap[V]() {
+					return fmt.Errorf("ini: line %d: section %v can not be %T", lineno, name, v)
+				}
+				sections = append(sections, section)
+				names = append(names, name)
+				direct = append(direct, ok)
+
+				if err := m.setDecoded(name, v); err != nil {
+					return fmt.Errorf("ini: line %d: %w", lineno, err)
+				}
+			}
+			m.attachLines(name, pending)
+			pending = nil
+			continue
+		}
+
+		eq := strings.IndexAny(line, "=:")
+		if eq < 0 {
+			return fmt.Errorf("ini: line %d: = is required", lineno)
+		}
+		key := strings.TrimSpace(line[:eq])
+		value := strings.TrimSpace(line[eq+1:])
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+
+		var err error
+		if section != nil {
+			err = section.setText(key, value, pending)
+		} else {
+			err = m.setText(key, value, pending)
+		}
+		if err != nil {
+			return fmt.Errorf("ini: line %d: %w", lineno, err)
+		}
+		pending = nil
+	}
+
+	if section != nil {
+		section.setTrailer(pending)
+	} else {
+		m.setTrailer(pending)
+	}
+
+	// sections not held by V as they are
+	for j, section := range sections {
+		if direct[j] {
+			continue
+		}
+
+		v, err := convertValue[V](section)
+		if err != nil {
+			return err
+		}
+		if e, found := m.m[m.mapKey(names[j])]; found {
+			e.v = v
+		}
+	}
+	return nil
+}
+
+// canHoldMap reports whether V may be converted from a map through encoding/json.
+func canHoldMap[V any]() bool {
+	t := reflect.TypeOf((*V)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Map, reflect.Struct, reflect.Interface:
+		return true
+	}
+	return reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem())
+}
+
+// newSection returns a new section, and it as V if V can hold it.
+func (m *OrderedMap[K, V]) newSection() (V, textMap, bool) {
+	if v, section, ok := m.newNested(); ok {
+		return v, section, true
+	}
+
+	section := New[string, string]()
+	section.comments = m.comments
+	v, ok := any(section).(V)
+	return v, section, ok
+}
@@ -0,0 +1,91 @@
+package orderedmap_test
+
+import (
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+const iniDoc = `; global
+name = app
+
+# servers
+[server]
+port = 8080
+host = "  localhost  "
+
+[database]
+; the user
+user = admin
+[server]
+tls = true
+
+; end
+`
+
+func TestUnmarshalINI(t *testing.T) {
+	m := orderedmap.New[string, any]()
+	err := m.UnmarshalINI([]byte(iniDoc))
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m.Keys(), []string{"name", "server", "database"})
+	gotwant.Test(t, m.GetDefault("name", nil), "app")
+
+	server := m.GetDefault("server", nil).(*orderedmap.OrderedMap[string, string])
+	gotwant.Test(t, server.Keys(), []string{"port", "host", "tls"})
+	gotwant.Test(t, server.GetDefault("host", ""), "  localhost  ")
+
+	t.Run("Typed", func(t *testing.T) {
+		m := orderedmap.New[string, *orderedmap.OrderedMap[string, int]]()
+		err := m.UnmarshalINI([]byte("[b]\ny = 1\nx = 2\n[a]\nz = 3\n"))
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"b", "a"})
+		gotwant.Test(t, m.GetDefault("b", nil).Keys(), []string{"y", "x"})
+		gotwant.Test(t, m.GetDefault("b", nil).Values(), []int{1, 2})
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		m := orderedmap.New[string, any]()
+		gotwant.TestError(t, m.UnmarshalINI([]byte("[open\n")), "] is required")
+		gotwant.TestError(t, m.UnmarshalINI([]byte("[s]\nnovalue\n")), "line 2: = is required")
+
+		flat := orderedmap.New[string, string]()
+		flat.Set("a", "1")
+		err := flat.UnmarshalINI([]byte("b = 2\n[s]\nc = 3\n"))
+		gotwant.TestError(t, err, "ini: line 2: section s can not be string")
+		gotwant.Test(t, flat.Keys(), []string{"a"})
+
+		typed := orderedmap.New[string, map[string]string]()
+		gotwant.TestError(t, typed.UnmarshalINI([]byte("[s]\nc = 3\n")), nil)
+		gotwant.Test(t, typed.GetDefault("s", nil), map[string]string{"c": "3"})
+	})
+}
+
+func TestMarshalINI(t *testing.T) {
+	m := orderedmap.New[string, any](orderedmap.WithComments(true))
+	err := m.UnmarshalINI([]byte(iniDoc))
+	gotwant.TestError(t, err, nil)
+
+	server := m.GetDefault("server", nil).(*orderedmap.OrderedMap[string, string])
+	server.Set("port", "9090")
+	m.Set("version", 2)
+
+	b, err := m.MarshalINI()
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b), `; global
+name = app
+version = 2
+
+# servers
+[server]
+port = 9090
+host = "  localhost  "
+tls = true
+
+; end
+
+[database]
+; the user
+user = admin
+`)
+}
@@ -0,0 +1,43 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// JSONMerger is implemented by values that MergeJSON merges into, instead of replacing them.
+// *OrderedMap implements it.
+type JSONMerger interface {
+	MergeJSON(b []byte) error
+}
+
+// MergeJSON decodes the JSON object b into m without clearing it.
+//
+// Existing keys are updated (following PreserveOrder) and new keys are appended.
+// Like encoding/json decoding into an existing struct, an existing value is decoded over;
+// a value implementing JSONMerger (nested OrderedMap) is merged recursively.
+func (m *OrderedMap[K, V]) MergeJSON(b []byte) error {
+	m.beginStep()
+	defer m.endStep()
+
+	return m.decodeJSON(b, func(key K, raw []byte) error {
+		value, found := m.Get(key)
+
+		if merger, ok := any(value).(JSONMerger); found && ok && !isNilJSONMerger(merger) && !bytes.Equal(raw, []byte("null")) {
+			if err := merger.MergeJSON(raw); err != nil {
+				return err
+			}
+		} else if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+
+		m.Set(key, value)
+		return nil
+	})
+}
+
+func isNilJSONMerger(merger JSONMerger) bool {
+	v := reflect.ValueOf(merger)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
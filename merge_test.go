@@ -0,0 +1,69 @@
+package orderedmap_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestMergeJSON(t *testing.T) {
+	t.Run("Flat", func(t *testing.T) {
+		m := orderedmap.New[string, int]()
+		err := m.UnmarshalJSON([]byte(`{"a":1,"b":2,"c":3}`))
+		gotwant.TestError(t, err, nil)
+
+		err = m.MergeJSON([]byte(`{"d":4,"b":20}`))
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"a", "b", "c", "d"})
+		gotwant.Test(t, m.Values(), []int{1, 20, 3, 4})
+
+		m.PreserveOrder(false)
+		err = m.MergeJSON([]byte(`{"a":10}`))
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"b", "c", "d", "a"})
+
+		err = m.MergeJSON([]byte(`{"a":"x"}`))
+		gotwant.TestError(t, err, "cannot unmarshal")
+	})
+
+	t.Run("Nest", func(t *testing.T) {
+		m := orderedmap.New[string, *orderedmap.OrderedMap[string, any]]()
+		err := json.Unmarshal([]byte(`{"server":{"host":"localhost","port":80},"log":{"level":"info"}}`), m)
+		gotwant.TestError(t, err, nil)
+		server := m.GetDefault("server", nil)
+
+		err = m.MergeJSON([]byte(`{"server":{"port":8080,"tls":true},"db":{"name":"x"},"log":null}`))
+		gotwant.TestError(t, err, nil)
+
+		b, err := json.Marshal(m)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, string(b), `{"server":{"host":"localhost","port":8080,"tls":true},"log":null,"db":{"name":"x"}}`)
+
+		// merged in place
+		gotwant.Test(t, m.GetDefault("server", nil) == server, true)
+	})
+
+	t.Run("Struct", func(t *testing.T) {
+		type conf struct {
+			Host string
+			Port int
+		}
+		m := orderedmap.New[string, *conf]()
+		m.Set("a", &conf{Host: "localhost", Port: 80})
+
+		err := m.MergeJSON([]byte(`{"a":{"Port":8080}}`))
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, *m.GetDefault("a", nil), conf{Host: "localhost", Port: 8080})
+	})
+
+	t.Run("History", func(t *testing.T) {
+		m := orderedmap.New[string, int](orderedmap.WithHistory(0))
+		m.Set("a", 1)
+		m.MergeJSON([]byte(`{"a":2,"b":3}`))
+		m.Undo()
+		gotwant.Test(t, m.Keys(), []string{"a"})
+		gotwant.Test(t, m.Values(), []int{1})
+	})
+}
@@ -0,0 +1,368 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// MarshalMsgpack encodes m as a MessagePack map, with the entries in order.
+func (m *OrderedMap[K, V]) MarshalMsgpack() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if m == nil {
+		buf.WriteByte(0xc0) // nil
+		return buf.Bytes(), nil
+	}
+
+	if err := encodeMsgpack(buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalMsgpack decodes the MessagePack map b into m.
+//
+// Nested maps are decoded into *OrderedMap[string, any], arrays into []any,
+// integers into int64 (uint64 if too large), then converted to V through encoding/json if V is not any.
+func (m *OrderedMap[K, V]) UnmarshalMsgpack(b []byte) error {
+	d := &msgpackDecoder{src: b}
+
+	defer m.beginDecode()()
+
+	if len(b) > 0 && b[0] == 0xc0 {
+		d.pos++
+	} else if err := d.readMap(m.setPlain); err != nil {
+		return err
+	}
+
+	if d.pos != len(b) {
+		return errors.New("msgpack: extra data")
+	}
+	return nil
+}
+
+func encodeMsgpack(buf *bytes.Buffer, v any) error {
+	p, err := plainValue(v)
+	if err != nil {
+		return err
+	}
+
+	switch x := p.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if x {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int64:
+		if x >= 0 {
+			writeMsgpackUint(buf, uint64(x))
+		} else if x >= -32 {
+			buf.WriteByte(byte(x))
+		} else if x >= math.MinInt8 {
+			buf.Write([]byte{0xd0, byte(x)})
+		} else if x >= math.MinInt16 {
+			buf.WriteByte(0xd1)
+			buf.Write(binary.BigEndian.AppendUint16(nil, uint16(x)))
+		} else if x >= math.MinInt32 {
+			buf.WriteByte(0xd2)
+			buf.Write(binary.BigEndian.AppendUint32(nil, uint32(x)))
+		} else {
+			buf.WriteByte(0xd3)
+			buf.Write(binary.BigEndian.AppendUint64(nil, uint64(x)))
+		}
+	case uint64:
+		writeMsgpackUint(buf, x)
+	case float64:
+		buf.WriteByte(0xcb)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(x)))
+	case string:
+		writeMsgpackHeader(buf, len(x), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buf.WriteString(x)
+	case []byte:
+		writeMsgpackHeader(buf, len(x), 0, 0, 0xc4, 0xc5, 0xc6)
+		buf.Write(x)
+	case time.Time:
+		// timestamp 96
+		buf.Write([]byte{0xc7, 12, 0xff})
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(x.Nanosecond())))
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(x.Unix())))
+
+	case []any:
+		writeMsgpackHeader(buf, len(x), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, elem := range x {
+			if err := encodeMsgpack(buf, elem); err != nil {
+				return err
+			}
+		}
+
+	case anyMap:
+		writeMsgpackHeader(buf, x.lenAny(), 0x80, 16, 0, 0xde, 0xdf)
+		return x.eachAny(func(key, value any) error {
+			if err := encodeMsgpack(buf, key); err != nil {
+				return err
+			}
+			return encodeMsgpack(buf, value)
+		})
+
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+	return nil
+}
+
+func writeMsgpackUint(buf *bytes.Buffer, u uint64) {
+	switch {
+	case u <= 0x7f:
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		buf.Write([]byte{0xcc, byte(u)})
+	case u <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(u)))
+	case u <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(u)))
+	default:
+		buf.WriteByte(0xcf)
+		buf.Write(binary.BigEndian.AppendUint64(nil, u))
+	}
+}
+
+// writeMsgpackHeader writes the length n in the smallest form.
+// fix is the first byte of the fix form holding up to fixLen, and 0 for the forms not existing.
+func writeMsgpackHeader(buf *bytes.Buffer, n int, fix byte, fixLen int, b8, b16, b32 byte) {
+	switch {
+	case fix != 0 && n < fixLen:
+		buf.WriteByte(fix | byte(n))
+	case b8 != 0 && n <= math.MaxUint8:
+		buf.Write([]byte{b8, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(b16)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(b32)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+type msgpackDecoder struct {
+	src []byte
+	pos int
+}
+
+var errMsgpackShort = errors.New("msgpack: unexpected end of data")
+
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.src)-d.pos < n {
+		return nil, errMsgpackShort
+	}
+	b := d.src[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// uint reads a big-endian unsigned integer of n bytes.
+func (d *msgpackDecoder) uint(n int) (uint64, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+// readMap reads a map calling fn for each entry.
+func (d *msgpackDecoder) readMap(fn func(key, value any) error) error {
+	c, err := d.uint(1)
+	if err != nil {
+		return err
+	}
+
+	var n uint64
+	switch {
+	case 0x80 <= c && c <= 0x8f:
+		n = c & 0x0f
+	case c == 0xde:
+		n, err = d.uint(2)
+	case c == 0xdf:
+		n, err = d.uint(4)
+	default:
+		return fmt.Errorf("msgpack: 0x%02x is not a map", c)
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := uint64(0); i < n; i++ {
+		key, err := d.read()
+		if err != nil {
+			return err
+		}
+		value, err := d.read()
+		if err != nil {
+			return err
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *msgpackDecoder) read() (any, error) {
+	if d.pos >= len(d.src) {
+		return nil, errMsgpackShort
+	}
+	c := d.src[d.pos]
+
+	switch {
+	case c <= 0x7f:
+		d.pos++
+		return int64(c), nil
+	case c >= 0xe0:
+		d.pos++
+		return int64(int8(c)), nil
+	case 0x80 <= c && c <= 0x8f, c == 0xde, c == 0xdf:
+		om := New[string, any]()
+		err := d.readMap(func(key, value any) error {
+			k, err := formatKey(key)
+			if err != nil {
+				return err
+			}
+			om.Set(k, value)
+			return nil
+		})
+		return om, err
+	case 0x90 <= c && c <= 0x9f:
+		d.pos++
+		return d.readArray(uint64(c & 0x0f))
+	case 0xa0 <= c && c <= 0xbf:
+		d.pos++
+		b, err := d.next(int(c & 0x1f))
+		return string(b), err
+	}
+
+	d.pos++
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+
+	case 0xc4, 0xc5, 0xc6, 0xd9, 0xda, 0xdb: // bin, str
+		size := map[byte]int{0xc4: 1, 0xc5: 2, 0xc6: 4, 0xd9: 1, 0xda: 2, 0xdb: 4}[c]
+		n, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(int(n))
+		if err != nil {
+			return nil, err
+		}
+		if c >= 0xd9 {
+			return string(b), nil
+		}
+		return append([]byte(nil), b...), nil
+
+	case 0xc7, 0xc8, 0xc9: // ext
+		size := map[byte]int{0xc7: 1, 0xc8: 2, 0xc9: 4}[c]
+		n, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		return d.readExt(int(n))
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8: // fixext
+		return d.readExt(1 << (c - 0xd4))
+
+	case 0xca:
+		u, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := d.uint(8)
+		return math.Float64frombits(u), err
+
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (c - 0xcc))
+		if u > math.MaxInt64 {
+			return u, err
+		}
+		return int64(u), err
+	case 0xd0:
+		u, err := d.uint(1)
+		return int64(int8(u)), err
+	case 0xd1:
+		u, err := d.uint(2)
+		return int64(int16(u)), err
+	case 0xd2:
+		u, err := d.uint(4)
+		return int64(int32(u)), err
+	case 0xd3:
+		u, err := d.uint(8)
+		return int64(u), err
+
+	case 0xdc, 0xdd:
+		n, err := d.uint(map[byte]int{0xdc: 2, 0xdd: 4}[c])
+		if err != nil {
+			return nil, err
+		}
+		return d.readArray(n)
+	}
+
+	return nil, fmt.Errorf("msgpack: invalid byte 0x%02x", c)
+}
+
+func (d *msgpackDecoder) readArray(n uint64) ([]any, error) {
+	if n > uint64(len(d.src)-d.pos) {
+		// each element takes a byte at least
+		return nil, errMsgpackShort
+	}
+
+	list := make([]any, 0, n)
+	for i := uint64(0); i < n; i++ {
+		v, err := d.read()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+// readExt reads the type and the data of an extension.
+// Only the timestamp (-1) is supported.
+func (d *msgpackDecoder) readExt(n int) (any, error) {
+	typ, err := d.uint(1)
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ) != -1 {
+		return nil, fmt.Errorf("msgpack: unsupported extension type %d", int8(typ))
+	}
+
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(b)), 0), nil
+	case 8:
+		u := binary.BigEndian.Uint64(b)
+		return time.Unix(int64(u&(1<<34-1)), int64(u>>34)), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(b[4:])), int64(binary.BigEndian.Uint32(b))), nil
+	}
+	return nil, fmt.Errorf("msgpack: invalid timestamp length %d", n)
+}
@@ -0,0 +1,64 @@
+package orderedmap_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestMsgpack(t *testing.T) {
+	sub := orderedmap.New[string, any]()
+	sub.Set("y", -1)
+	sub.Set("x", []any{1, "a"})
+
+	m := orderedmap.New[string, any]()
+	m.Set("b", true)
+	m.Set("a", sub)
+	m.Set("c", nil)
+
+	b, err := m.MarshalMsgpack()
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, b, []byte{
+		0x83,
+		0xa1, 'b', 0xc3,
+		0xa1, 'a', 0x82,
+		/**/ 0xa1, 'y', 0xff,
+		/**/ 0xa1, 'x', 0x92, 0x01, 0xa1, 'a',
+		0xa1, 'c', 0xc0,
+	})
+
+	m2 := orderedmap.New[string, any]()
+	err = m2.UnmarshalMsgpack(b)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m2.Keys(), []string{"b", "a", "c"})
+	sub2 := m2.GetDefault("a", nil).(*orderedmap.OrderedMap[string, any])
+	gotwant.Test(t, sub2.Keys(), []string{"y", "x"})
+	gotwant.Test(t, sub2.GetDefault("y", nil), int64(-1))
+	gotwant.Test(t, sub2.GetDefault("x", nil), []any{int64(1), "a"})
+
+	t.Run("Typed", func(t *testing.T) {
+		now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+		m := orderedmap.New[int, time.Time]()
+		m.Set(300, now)
+		m.Set(-5, now.Add(time.Hour))
+
+		b, err := m.MarshalMsgpack()
+		gotwant.TestError(t, err, nil)
+
+		m2 := orderedmap.New[int, time.Time]()
+		err = m2.UnmarshalMsgpack(b)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m2.Keys(), []int{300, -5})
+		gotwant.Test(t, m2.GetDefault(300, time.Time{}).Equal(now), true)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		m := orderedmap.New[string, any]()
+		gotwant.TestError(t, m.UnmarshalMsgpack([]byte{0x92, 0x01, 0x02}), "not a map")
+		gotwant.TestError(t, m.UnmarshalMsgpack([]byte{0x81, 0xa1}), "unexpected end")
+		gotwant.TestError(t, m.UnmarshalMsgpack([]byte{0x80, 0x00}), "extra data")
+	})
+}
@@ -0,0 +1,183 @@
+package orderedmap
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Option configures a map made by New or NewSorted.
+type Option func(*options)
+
+type options struct {
+	capacity      int
+	preserveOrder bool
+	duplicate     DuplicatePolicy
+	normalizer    any
+	valueConflict ValueConflictPolicy
+	history       bool
+	historyLimit  int
+	xml           xmlStyle
+	comments      bool
+}
+
+// DuplicatePolicy decides what UnmarshalJSON and UnmarshalYAML do with a key appearing twice in a document.
+type DuplicatePolicy int
+
+const (
+	// DuplicateLastWins keeps the last value. (default)
+	DuplicateLastWins DuplicatePolicy = iota
+	// DuplicateFirstWins keeps the first value.
+	DuplicateFirstWins
+	// DuplicateError makes decoding fail.
+	DuplicateError
+)
+
+// WithCapacity allocates room for n entries. A negative n is taken as 0.
+func WithCapacity(n int) Option {
+	return func(o *options) {
+		if n < 0 {
+			n = 0
+		}
+		o.capacity = n
+	}
+}
+
+// WithPreserveOrder is the same as calling PreserveOrder(b).
+func WithPreserveOrder(b bool) Option {
+	return func(o *options) {
+		o.preserveOrder = b
+	}
+}
+
+// WithDuplicatePolicy sets the policy for duplicate keys in decoded documents.
+func WithDuplicatePolicy(p DuplicatePolicy) Option {
+	return func(o *options) {
+		o.duplicate = p
+	}
+}
+
+// WithKeyNormalizer makes keys that normalize to the same key identical.
+// Keys keep the spelling of their first insertion.
+//
+// normalize is a func(K) K of the map, or a func(T) T where T has the same underlying type as K
+// (such as FoldCase for a map of type Header string), which is called through conversions.
+// New panics on the other types; NormalizeKeys is checked by the compiler instead.
+func WithKeyNormalizer[K comparable](normalize func(K) K) Option {
+	return func(o *options) {
+		o.normalizer = normalize
+	}
+}
+
+// convertNormalizer adapts a func(T) T to func(K) K if T and K have the same underlying type.
+func convertNormalizer[K comparable](normalize any) (func(K) K, bool) {
+	fv := reflect.ValueOf(normalize)
+	ft := fv.Type()
+	kt := reflect.TypeOf((*K)(nil)).Elem()
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 1 || ft.IsVariadic() {
+		return nil, false
+	}
+
+	t := ft.In(0)
+	if ft.Out(0) != t || t.Kind() != kt.Kind() || kt.Kind() == reflect.Interface ||
+		!kt.ConvertibleTo(t) || !t.ConvertibleTo(kt) {
+		return nil, false
+	}
+
+	return func(k K) K {
+		out := fv.Call([]reflect.Value{reflect.ValueOf(k).Convert(t)})
+		return out[0].Convert(kt).Interface().(K)
+	}, true
+}
+
+// FoldCase is a key normalizer for Unicode case-insensitive keys.
+func FoldCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		// the smallest rune in the orbit of simple case folding
+		folded := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < folded {
+				folded = f
+			}
+		}
+		return folded
+	}, s)
+}
+
+// NormalizeKeys sets the key normalizer like WithKeyNormalizer, typed by the map.
+// A nil normalize makes keys exact again.
+//
+// The existing keys are normalized too.
+// If some of them become identical, NormalizeKeys returns ErrKeyCollision and m is not changed.
+func (m *OrderedMap[K, V]) NormalizeKeys(normalize func(K) K) error {
+	if m == nil {
+		return nil
+	}
+
+	mm := make(map[K]*elem[K, V], len(m.m))
+	for e := m.first(); e != nil; e = e.next() {
+		k := e.key
+		if normalize != nil {
+			k = normalize(k)
+		}
+		if _, found := mm[k]; found {
+			return ErrKeyCollision
+		}
+		mm[k] = e
+	}
+
+	m.m = mm
+	m.norm = normalize
+	return nil
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		preserveOrder: true,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Grow allocates room for n more entries.
+func (m *OrderedMap[K, V]) Grow(n int) {
+	if m == nil || n <= 0 {
+		return
+	}
+
+	// a map can be grown only by rebuilding
+	mm := make(map[K]*elem[K, V], len(m.m)+n)
+	for k, e := range m.m {
+		mm[k] = e
+	}
+	m.m = mm
+}
+
+// Clip releases the memory left unused after deletions.
+func (m *OrderedMap[K, V]) Clip() {
+	if m == nil {
+		return
+	}
+
+	mm := make(map[K]*elem[K, V], len(m.m))
+	for k, e := range m.m {
+		mm[k] = e
+	}
+	m.m = mm
+}
+
+// setDecoded sets a key-value pair decoded from a document, following the duplicate policy.
+func (m *OrderedMap[K, V]) setDecoded(key K, value V) error {
+	if m.duplicate != DuplicateLastWins && m.Contains(key) {
+		if m.duplicate == DuplicateError {
+			return fmt.Errorf("duplicate key: %v", key)
+		}
+		return nil
+	}
+
+	m.Set(key, value)
+	return nil
+}
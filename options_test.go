@@ -0,0 +1,205 @@
+package orderedmap_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestOptions(t *testing.T) {
+	t.Run("Capacity", func(t *testing.T) {
+		m := orderedmap.New[int, int](orderedmap.WithCapacity(100))
+		for i := 0; i < 100; i++ {
+			m.Set(i, i)
+		}
+		gotwant.Test(t, m.Len(), 100)
+		gotwant.Test(t, m.IndexOf(99), 99)
+
+		m = orderedmap.New[int, int](orderedmap.WithCapacity(-1))
+		m.Set(1, 1)
+		gotwant.Test(t, m.Len(), 1)
+	})
+
+	t.Run("PreserveOrder", func(t *testing.T) {
+		m := orderedmap.New[int, int](orderedmap.WithPreserveOrder(false))
+		m.Set(1, 0)
+		m.Set(2, 0)
+		m.Set(1, 0)
+		gotwant.Test(t, m.Keys(), []int{2, 1})
+	})
+
+	t.Run("Sorted", func(t *testing.T) {
+		m := orderedmap.NewSorted[int, int](intcmp, orderedmap.WithCapacity(10))
+		m.Set(2, 0)
+		m.Set(1, 0)
+		gotwant.Test(t, m.Keys(), []int{1, 2})
+	})
+}
+
+func TestDuplicatePolicy(t *testing.T) {
+	data := `{"a":1,"b":2,"a":3}`
+	yamlData := "a: 1\nb: 2\na: 3\n"
+
+	t.Run("LastWins", func(t *testing.T) {
+		m := orderedmap.New[string, int]()
+		err := json.Unmarshal([]byte(data), m)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"a", "b"})
+		gotwant.Test(t, m.GetDefault("a", 0), 3)
+	})
+
+	t.Run("FirstWins", func(t *testing.T) {
+		m := orderedmap.New[string, int](orderedmap.WithDuplicatePolicy(orderedmap.DuplicateFirstWins))
+		err := json.Unmarshal([]byte(data), m)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"a", "b"})
+		gotwant.Test(t, m.GetDefault("a", 0), 1)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		m := orderedmap.New[string, int](orderedmap.WithDuplicatePolicy(orderedmap.DuplicateError))
+		err := json.Unmarshal([]byte(data), m)
+		gotwant.TestError(t, err, "duplicate key: a")
+
+		// kept after decoding
+		err = json.Unmarshal([]byte(data), m)
+		gotwant.TestError(t, err, "duplicate key: a")
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		// yaml.v3 itself rejects duplicate keys in a document
+		m := orderedmap.New[string, int](orderedmap.WithDuplicatePolicy(orderedmap.DuplicateFirstWins))
+		node := yaml.Node{}
+		err := yaml.Unmarshal([]byte(yamlData), &node)
+		gotwant.TestError(t, err, nil)
+		err = m.UnmarshalYAML(node.Content[0])
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.GetDefault("a", 0), 1)
+	})
+}
+
+func TestGrowClip(t *testing.T) {
+	m := orderedmap.New[int, int]()
+	m.Grow(1000)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+	for i := 0; i < 1000; i++ {
+		if i%10 != 0 {
+			m.Delete(i)
+		}
+	}
+	m.Clip()
+	gotwant.Test(t, m.Len(), 100)
+	gotwant.Test(t, m.IndexOf(990), 99)
+
+	m.Set(1, 1)
+	gotwant.Test(t, m.IndexOf(1), 100)
+
+	var nilm *orderedmap.OrderedMap[int, int]
+	nilm.Grow(10)
+	nilm.Clip()
+}
+
+func TestKeyNormalizer(t *testing.T) {
+	m := orderedmap.New[string, string](orderedmap.WithKeyNormalizer(strings.ToLower))
+	m.Set("Content-Type", "text/plain")
+	m.Set("X-Request-ID", "1")
+	m.Set("content-type", "application/json")
+
+	gotwant.Test(t, m.Len(), 2)
+	gotwant.Test(t, m.Keys(), []string{"Content-Type", "X-Request-ID"})
+	gotwant.Test(t, m.GetDefault("CONTENT-TYPE", ""), "application/json")
+	gotwant.Test(t, m.Contains("x-request-id"), true)
+	gotwant.Test(t, m.IndexOf("x-request-id"), 1)
+	gotwant.Test(t, m.UnorderedMap(), map[string]string{"Content-Type": "application/json", "X-Request-ID": "1"})
+
+	b, err := json.Marshal(m)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b), `{"Content-Type":"application/json","X-Request-ID":"1"}`)
+
+	m.Delete("x-request-id")
+	gotwant.Test(t, m.Keys(), []string{"Content-Type"})
+
+	err = json.Unmarshal([]byte(`{"Accept":"*/*","ACCEPT":"text/html"}`), m)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m.Keys(), []string{"Accept"})
+	gotwant.Test(t, m.GetDefault("accept", ""), "text/html")
+
+	f := orderedmap.Filter(m, func(string, string) bool { return true })
+	gotwant.Test(t, f.Contains("ACCEPT"), true)
+
+	gotwant.TestPanic(t, func() {
+		orderedmap.New[int, string](orderedmap.WithKeyNormalizer(strings.ToLower))
+	}, "orderedmap: key normalizer must be func(int) int")
+
+	type ID int
+	gotwant.TestPanic(t, func() {
+		orderedmap.New[ID, string](orderedmap.WithKeyNormalizer(strings.ToLower))
+	}, "orderedmap: key normalizer must be func(orderedmap_test.ID) orderedmap_test.ID")
+
+	t.Run("Underlying", func(t *testing.T) {
+		type Header string
+		h := orderedmap.New[Header, string](orderedmap.WithKeyNormalizer(orderedmap.FoldCase))
+		h.Set("Content-Type", "text/plain")
+		h.Set("content-type", "application/json")
+		gotwant.Test(t, h.Keys(), []Header{"Content-Type"})
+		gotwant.Test(t, h.GetDefault("CONTENT-TYPE", ""), "application/json")
+	})
+}
+
+func TestNormalizeKeys(t *testing.T) {
+	type Header string
+
+	m := orderedmap.New[Header, string]()
+	m.Set("Content-Type", "text/plain")
+	m.Set("X-Request-ID", "1")
+
+	err := m.NormalizeKeys(func(h Header) Header {
+		return Header(orderedmap.FoldCase(string(h)))
+	})
+	gotwant.TestError(t, err, nil)
+	m.Set("content-type", "application/json")
+	gotwant.Test(t, m.Keys(), []Header{"Content-Type", "X-Request-ID"})
+	gotwant.Test(t, m.GetDefault("x-request-id", ""), "1")
+
+	gotwant.TestError(t, m.NormalizeKeys(nil), nil)
+	gotwant.Test(t, m.Contains("x-request-id"), false)
+	gotwant.Test(t, m.Contains("X-Request-ID"), true)
+
+	t.Run("Collision", func(t *testing.T) {
+		m := orderedmap.New[string, int]()
+		m.Set("a", 1)
+		m.Set("A", 2)
+		gotwant.TestError(t, m.NormalizeKeys(strings.ToLower), orderedmap.ErrKeyCollision)
+		gotwant.Test(t, m.Contains("A"), true)
+		gotwant.Test(t, m.Contains("b"), false)
+		gotwant.Test(t, m.GetDefault("a", 0), 1)
+
+		m.Set("b", 3)
+		gotwant.Test(t, m.Keys(), []string{"a", "A", "b"})
+	})
+}
+
+func TestFoldCase(t *testing.T) {
+	m := orderedmap.New[string, int](orderedmap.WithKeyNormalizer(orderedmap.FoldCase))
+	m.Set("Straße", 1)
+	m.Set("STRASSE", 2) // not a simple folding
+	m.Set("ΣΊΣΥΦΟΣ", 3)
+	m.Set("σίσυφος", 4)
+	m.Set("K", 5)
+	m.Set("K", 6) // Kelvin sign
+
+	gotwant.Test(t, m.Keys(), []string{"Straße", "STRASSE", "ΣΊΣΥΦΟΣ", "K"})
+	gotwant.Test(t, m.GetDefault("k", 0), 6)
+	gotwant.Test(t, m.GetDefault("σίσυφοσ", 0), 4)
+
+	trimmed := orderedmap.New[string, int](orderedmap.WithKeyNormalizer(strings.TrimSpace))
+	trimmed.Set(" a ", 1)
+	gotwant.Test(t, trimmed.GetDefault("a", 0), 1)
+}
@@ -7,36 +7,93 @@ import (
 	"fmt"
 	"path"
 	"reflect"
-	"sort"
 	"strings"
 
 	"github.com/shu-go/jbdec"
 	"gopkg.in/yaml.v3"
 )
 
-type elem[V any] struct {
-	v V
+type elem[K comparable, V any] struct {
+	key K
+	v   V
 
-	maxIdx int
+	// position in the order (index.go)
+	parent, left, right *elem[K, V]
+	size                int
+	prio                uint64
+
+	// comments (WithComments)
+	note *note
 }
 
 type OrderedMap[K comparable, V any] struct {
-	m map[K]*elem[V]
+	m map[K]*elem[K, V]
 
-	keys []K
+	// entries in order (index.go)
+	root *elem[K, V]
+	// for the priorities of the entries
+	seed uint64
 
 	overwriteSeq bool
 
+	// cmp keeps the keys sorted (NewSorted)
+	cmp func(a, b K) int
+
+	// norm maps a key to the key of m (WithKeyNormalizer)
+	norm func(K) K
+
+	duplicate DuplicatePolicy
+
+	// WithXMLAttributes, WithXMLEntry
+	xml xmlStyle
+
+	// WithComments
+	comments bool
+	// comment and blank lines after the last entry
+	trailer []string
+	// the mapping node without its content (YAML)
+	yamlMeta *yaml.Node
+
+	observers []*observer[K, V]
+	// no events while decoding
+	muted bool
+
+	// changes in Tx
+	txlog  []change[K, V]
+	intx   bool
+	openTx *Tx[K, V] // the innermost
+
+	// undo/redo (WithHistory)
+	history *history[K, V]
+
 	work bytes.Buffer
 }
 
-func New[K comparable, V any]() *OrderedMap[K, V] {
-	return &OrderedMap[K, V]{
-		m:    make(map[K]*elem[V]),
-		keys: nil,
+func New[K comparable, V any](opts ...Option) *OrderedMap[K, V] {
+	o := newOptions(opts)
 
-		overwriteSeq: false,
+	m := &OrderedMap[K, V]{
+		m: make(map[K]*elem[K, V], o.capacity),
+
+		overwriteSeq: !o.preserveOrder,
+		duplicate:    o.duplicate,
+		xml:          o.xml,
+		comments:     o.comments,
+	}
+	if o.history {
+		m.history = &history[K, V]{limit: o.historyLimit}
+	}
+	if o.normalizer != nil {
+		norm, ok := o.normalizer.(func(K) K)
+		if !ok {
+			norm, ok = convertNormalizer[K](o.normalizer)
+		}
+		if !ok {
+			panic(fmt.Sprintf("orderedmap: key normalizer must be %T", m.norm))
+		}
+		m.norm = norm
 	}
+	return m
 }
 
 func (m *OrderedMap[K, V]) PreserveOrder(b bool) {
@@ -48,20 +105,52 @@ func (m *OrderedMap[K, V]) Set(key K, value V) {
 		panic("assignment to entry in nil map")
 	}
 
-	if e, found := m.m[key]; !found {
-		m.keys = append(m.keys, key)
-		m.m[key] = &elem[V]{
-			maxIdx: len(m.keys),
-			v:      value,
+	if e, found := m.m[m.mapKey(key)]; !found {
+		e = &elem[K, V]{
+			key: key,
+			v:   value,
+		}
+
+		if m.cmp != nil {
+			m.insertAt(m.search(key, false), e)
+		} else {
+			m.push(e)
+		}
+		m.m[m.mapKey(key)] = e
+
+		if m.tracking() {
+			m.changed(change[K, V]{
+				Event: Event[K, V]{
+					Kind:     EventInsert,
+					Key:      e.key,
+					NewValue: value,
+					OldIndex: -1,
+					NewIndex: e.index(),
+				},
+				e: e,
+			})
 		}
 
 	} else {
+		ev := Event[K, V]{
+			Kind:     EventUpdate,
+			Key:      e.key,
+			OldValue: e.v,
+			NewValue: value,
+		}
+		if m.tracking() {
+			ev.OldIndex = e.index()
+		}
+
 		e.v = value
-		if m.overwriteSeq {
-			idx := m.indexOfKey(key, e.maxIdx)
-			e.maxIdx = idx
-			m.keys = append(m.keys[:idx], m.keys[idx+1:]...)
-			m.keys = append(m.keys, key)
+		if m.overwriteSeq && m.cmp == nil {
+			m.unlink(e)
+			m.push(e)
+		}
+
+		if m.tracking() {
+			ev.NewIndex = e.index()
+			m.changed(change[K, V]{Event: ev, e: e})
 		}
 	}
 }
@@ -71,16 +160,44 @@ func (m *OrderedMap[K, V]) Delete(key K) {
 		return
 	}
 
-	e, found := m.m[key]
+	e, found := m.m[m.mapKey(key)]
 
 	if !found {
 		return
 	}
-	delete(m.m, key)
+	delete(m.m, m.mapKey(key))
+
+	var idx int
+	if m.tracking() {
+		idx = e.index()
+	}
+
+	m.unlink(e)
+
+	if m.tracking() {
+		m.changed(change[K, V]{
+			Event: Event[K, V]{
+				Kind:     EventDelete,
+				Key:      e.key,
+				OldValue: e.v,
+				OldIndex: idx,
+				NewIndex: -1,
+			},
+			e: e,
+		})
+	}
+}
+
+// Clear deletes all the entries.
+// The allocated memory and the configuration (PreserveOrder, ...) are kept.
+func (m *OrderedMap[K, V]) Clear() {
+	if m == nil {
+		return
+	}
 
-	idx := m.indexOfKey(key, e.maxIdx)
-	e.maxIdx = idx
-	m.keys = append(m.keys[:idx], m.keys[idx+1:]...)
+	before := m.snapshot()
+	m.reset()
+	m.changedWhole(EventClear, before)
 }
 
 func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
@@ -89,7 +206,7 @@ func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
 		return gnil, false
 	}
 
-	if e, found := m.m[key]; found {
+	if e, found := m.m[m.mapKey(key)]; found {
 		return e.v, true
 	}
 
@@ -113,15 +230,28 @@ func (m *OrderedMap[K, V]) Len() int {
 	if m == nil {
 		return 0
 	}
-	return len(m.keys)
+	return len(m.m)
 }
 
+// Keys returns a copy of the keys in order.
 func (m *OrderedMap[K, V]) Keys() []K {
-	if m == nil {
+	if m == nil || m.root == nil {
 		return nil
 	}
 
-	return m.keys
+	return m.AppendKeys(make([]K, 0, len(m.m)))
+}
+
+// AppendKeys appends the keys in order to dst and returns the extended slice.
+func (m *OrderedMap[K, V]) AppendKeys(dst []K) []K {
+	if m == nil {
+		return dst
+	}
+
+	for e := m.first(); e != nil; e = e.next() {
+		dst = append(dst, e.key)
+	}
+	return dst
 }
 
 func (m *OrderedMap[K, V]) Contains(key K) bool {
@@ -129,7 +259,7 @@ func (m *OrderedMap[K, V]) Contains(key K) bool {
 		return false
 	}
 
-	_, found := m.m[key]
+	_, found := m.m[m.mapKey(key)]
 	return found
 }
 
@@ -144,15 +274,14 @@ func (m *OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
 
 	buf.WriteByte('{')
 
-	for i, k := range m.keys {
-		e, found := m.m[k]
-		if !found {
-			continue
-		}
+	first := true
+	for e := m.first(); e != nil; e = e.next() {
+		k := e.key
 
-		if i > 0 {
+		if !first {
 			buf.WriteByte(',')
 		}
+		first = false
 
 		if s, ok := any(k).(string); ok {
 			buf.WriteByte('"')
@@ -183,18 +312,32 @@ func (m *OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// UnmarshalJSON decodes b into m.
+//
+// m is cleared first, but PreserveOrder and the other settings are kept.
+// So are the other Unmarshal methods (YAML, TOML, XML and so on).
 func (m *OrderedMap[K, V]) UnmarshalJSON(b []byte) error {
-	*m = *New[K, V]() // clear
+	defer m.beginDecode()()
+
+	return m.decodeJSON(b, func(key K, raw []byte) error {
+		var value V // fresh, not to share maps or slices between values
+		err := json.Unmarshal(raw, &value)
+		if err != nil {
+			return err
+		}
+		//log.Printf("%v: %#v", key, value)
 
+		return m.setDecoded(key, value)
+	})
+}
+
+// decodeJSON calls set for each key and its raw value of the JSON object b, in order.
+func (m *OrderedMap[K, V]) decodeJSON(b []byte, set func(key K, raw []byte) error) error {
 	if len(b) == 0 {
 		return nil
 	}
 
-	m.m = make(map[K]*elem[V])
-	m.keys = m.keys[:0]
-
 	var key K
-	var value V
 
 	parsingKey := true
 	valueBuf := &m.work
@@ -315,13 +458,9 @@ func (m *OrderedMap[K, V]) UnmarshalJSON(b []byte) error {
 		if len(stack) == 0 {
 			//log.Printf("%v: %q", key, valueBuf.String())
 
-			err := json.Unmarshal(valueBuf.Bytes(), &value)
-			if err != nil {
+			if err := set(key, valueBuf.Bytes()); err != nil {
 				return err
 			}
-			//log.Printf("%v: %#v", key, value)
-
-			m.Set(key, value)
 
 			parsingKey = true
 		}
@@ -331,26 +470,30 @@ func (m *OrderedMap[K, V]) UnmarshalJSON(b []byte) error {
 }
 
 func (m *OrderedMap[K, V]) MarshalYAML() (any, error) {
-	if m == nil || len(m.keys) == 0 {
+	if m != nil && m.comments {
+		return m.yamlNode()
+	}
+	if m == nil || len(m.m) == 0 {
 		return nil, nil
 	}
 
 	var v V
 	vtype := reflect.TypeOf(v)
-	fields := make([]reflect.StructField, 0, len(m.keys))
-	for i := 0; i < len(m.keys); i++ {
+	keys := m.Keys()
+	fields := make([]reflect.StructField, 0, len(keys))
+	for i := 0; i < len(keys); i++ {
 		fields = append(fields, reflect.StructField{
 			Name: fmt.Sprintf("F%05d", i),
 			Type: vtype,
-			Tag:  reflect.StructTag(fmt.Sprintf(`yaml:"%v"`, m.keys[i])),
+			Tag:  reflect.StructTag(fmt.Sprintf(`yaml:"%v"`, keys[i])),
 		})
 	}
 
 	stType := reflect.StructOf(fields)
 	st := reflect.New(stType).Elem()
 
-	for i := 0; i < len(m.keys); i++ {
-		st.FieldByIndex([]int{i}).Set(reflect.ValueOf(m.GetDefault(m.keys[i], v)))
+	for i := 0; i < len(keys); i++ {
+		st.FieldByIndex([]int{i}).Set(reflect.ValueOf(m.GetDefault(keys[i], v)))
 	}
 
 	return st.Interface(), nil
@@ -358,7 +501,11 @@ func (m *OrderedMap[K, V]) MarshalYAML() (any, error) {
 
 // NOT SUPPORTED: number key, nested OrderedMap
 func (m *OrderedMap[K, V]) UnmarshalYAML(value *yaml.Node) error {
-	*m = *New[K, V]() // clear
+	defer m.beginDecode()()
+
+	if m.comments {
+		m.yamlMeta = yamlMeta(value)
+	}
 
 	for i := 0; i < len(value.Content); i += 2 {
 		key := value.Content[i]
@@ -373,15 +520,24 @@ func (m *OrderedMap[K, V]) UnmarshalYAML(value *yaml.Node) error {
 		if err := key.Decode(&k); err != nil {
 			if len(key.Value) >= 2 && key.Value[0] == '"' && key.Value[len(key.Value)-1] == '"' {
 				key.Value = key.Value[1 : len(key.Value)-1]
+				key.Tag, key.Style = "", 0 // resolve as plain
 				err = key.Decode(&k)
 			}
-			return err
+			if err != nil {
+				return err
+			}
+		}
+		if m.comments {
+			v, _, _ = m.newNested()
 		}
 		if err := val.Decode(&v); err != nil {
 			return err
 		}
 
-		m.Set(k, v)
+		if err := m.setDecoded(k, v); err != nil {
+			return err
+		}
+		m.keepYAML(k, key, val)
 	}
 
 	return nil
@@ -390,40 +546,13 @@ func (m *OrderedMap[K, V]) UnmarshalYAML(value *yaml.Node) error {
 func (m *OrderedMap[K, V]) UnorderedMap() map[K]V {
 	u := make(map[K]V)
 
-	for k, e := range m.m {
-		u[k] = e.v
+	for _, e := range m.m {
+		u[e.key] = e.v
 	}
 
 	return u
 }
 
-func (m *OrderedMap[K, V]) Sort(less func(K, K) bool) {
-	handler := SliceHandler{
-		len: func() int {
-			return len(m.keys)
-		},
-		less: func(i, j int) bool {
-			return less(m.keys[i], m.keys[j])
-		},
-		swap: func(i, j int) {
-			ikey := m.keys[i]
-			jkey := m.keys[j]
-			ielem := m.m[ikey]
-			jelem := m.m[jkey]
-
-			m.keys[i], m.keys[j] = m.keys[j], m.keys[i]
-
-			// swap elems to swap maxIdx
-			ielem.maxIdx = j
-			jelem.maxIdx = i
-			m.m[ikey] = ielem
-			m.m[jkey] = jelem
-		},
-	}
-
-	sort.Sort(handler)
-}
-
 func (m OrderedMap[K, V]) Format(s fmt.State, verb rune) {
 	sb := &strings.Builder{}
 
@@ -445,31 +574,31 @@ func (m OrderedMap[K, V]) Format(s fmt.State, verb rune) {
 		sb.WriteByte(']')
 		sb.WriteString(vname)
 		sb.WriteByte('{')
-		for i, k := range m.keys {
+		for i, e := range m.Entries() {
 			if i != 0 {
 				sb.WriteString(", ")
 			}
-			fmt.Fprintf(sb, "%#v:%#v", k, m.m[k].v)
+			fmt.Fprintf(sb, "%#v:%#v", e.Key, e.Value)
 		}
 		sb.WriteByte('}')
 
 	case s.Flag('+'):
 		sb.WriteString("OrderedMap[")
-		for i, k := range m.keys {
+		for i, e := range m.Entries() {
 			if i != 0 {
 				sb.WriteByte(' ')
 			}
-			fmt.Fprintf(sb, "%+v:%+v", k, m.m[k].v)
+			fmt.Fprintf(sb, "%+v:%+v", e.Key, e.Value)
 		}
 		sb.WriteByte(']')
 
 	default:
 		sb.WriteString("OrderedMap[")
-		for i, k := range m.keys {
+		for i, e := range m.Entries() {
 			if i != 0 {
 				sb.WriteByte(' ')
 			}
-			fmt.Fprint(sb, k, ":", m.m[k].v)
+			fmt.Fprint(sb, e.Key, ":", e.Value)
 		}
 		sb.WriteByte(']')
 	}
@@ -477,44 +606,38 @@ func (m OrderedMap[K, V]) Format(s fmt.State, verb rune) {
 	fmt.Fprint(s, sb.String())
 }
 
-type SliceHandler struct {
-	len  func() int
-	less func(i, j int) bool
-	swap func(i, j int)
-}
-
-func (h SliceHandler) Len() int {
-	return h.len()
-}
-
-func (h SliceHandler) Less(i, j int) bool {
-	return h.less(i, j)
-}
-
-func (h SliceHandler) Swap(i, j int) {
-	h.swap(i, j)
+// mapKey returns the key of m.m for key.
+func (m *OrderedMap[K, V]) mapKey(key K) K {
+	if m.norm != nil {
+		return m.norm(key)
+	}
+	return key
 }
 
-func (m *OrderedMap[K, V]) indexOfKey(key K, maxIdx int) int {
-	start := maxIdx
-	if len(m.keys)-1 < start {
-		start = len(m.keys) - 1
+// blank returns an empty map with the settings of m, without observers nor history.
+func (m *OrderedMap[K, V]) blank() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		m:            make(map[K]*elem[K, V]),
+		overwriteSeq: m.overwriteSeq,
+		cmp:          m.cmp,
+		norm:         m.norm,
+		duplicate:    m.duplicate,
+		xml:          m.xml,
+		comments:     m.comments,
 	}
+}
 
-	idx := -1
-	for i := start; i >= 0; i-- {
-		if m.keys[i] == key {
-			idx = i
-			break
-		}
-	}
-	if idx == -1 {
-		for i := len(m.keys) - 1; i >= 0; i-- {
-			if m.keys[i] == key {
-				idx = i
-				break
-			}
+// reset removes all the entries, keeping the configuration and the allocated memory.
+func (m *OrderedMap[K, V]) reset() {
+	if m.m == nil {
+		m.m = make(map[K]*elem[K, V])
+	} else {
+		for k := range m.m {
+			delete(m.m, k)
 		}
 	}
-	return idx
+
+	m.root = nil
+	m.trailer = nil
+	m.yamlMeta = nil
 }
@@ -622,6 +622,16 @@ b: 2`), &m)
 
 		gotwant.Test(t, m.Keys(), []int{9, 2})
 	})
+	t.Run("QuotedKeyYAML", func(t *testing.T) {
+		m := orderedmap.New[int, string]()
+		err := yaml.Unmarshal([]byte(`'"1"': a
+2: b
+`), &m)
+		gotwant.TestError(t, err, nil)
+
+		gotwant.Test(t, m.Keys(), []int{1, 2})
+		gotwant.Test(t, m.GetDefault(2, ""), "b")
+	})
 
 	t.Run("MyStruct", func(t *testing.T) {
 		type myStruct struct {
@@ -720,13 +730,18 @@ c:
 }`), &m)
 		gotwant.TestError(t, err, nil)
 
-		subm, found := m.Get("2")
+		subm, found := m.Get("1")
 		gotwant.Test(t, found, true)
 
 		v, found := subm.Get("sub2")
 		gotwant.Test(t, found, true)
 		gotwant.Test(t, v, "ni")
 
+		// each value is its own map
+		subm, found = m.Get("2")
+		gotwant.Test(t, found, true)
+		gotwant.Test(t, subm.Keys(), []string{"sub3"})
+
 		gotwant.Test(t, m.Keys(), []string{"2", "1"})
 
 	})
@@ -779,6 +794,44 @@ func TestPreserveOrder(t *testing.T) {
 	})
 }
 
+func TestClear(t *testing.T) {
+	m := orderedmap.New[int, int](orderedmap.WithKeyNormalizer(func(k int) int { return k % 100 }))
+	m.PreserveOrder(false)
+	for i := 0; i < 10; i++ {
+		m.Set(i, i)
+	}
+
+	m.Clear()
+	gotwant.Test(t, m.Len(), 0)
+	gotwant.Test(t, len(m.Keys()), 0)
+	gotwant.Test(t, m.Contains(1), false)
+
+	m.Set(1, 1)
+	m.Set(2, 2)
+	m.Set(101, 101)
+	gotwant.Test(t, m.Keys(), []int{2, 1})
+	gotwant.Test(t, m.GetDefault(1, 0), 101)
+
+	var nilm *orderedmap.OrderedMap[int, int]
+	nilm.Clear()
+}
+
+func TestUnmarshalKeepsPreserveOrder(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	m.PreserveOrder(false)
+
+	err := json.Unmarshal([]byte(`{"a":1,"b":2,"a":3}`), m)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m.Keys(), []string{"b", "a"})
+	m.Set("b", 4)
+	gotwant.Test(t, m.Keys(), []string{"a", "b"})
+
+	err = yaml.Unmarshal([]byte("x: 1\ny: 2\n"), m)
+	gotwant.TestError(t, err, nil)
+	m.Set("x", 3)
+	gotwant.Test(t, m.Keys(), []string{"y", "x"})
+}
+
 func TestDelete(t *testing.T) {
 	t.Run("Last", func(t *testing.T) {
 		m := orderedmap.New[int, int]()
@@ -858,6 +911,36 @@ func TestLen(t *testing.T) {
 	gotwant.Test(t, m.Len(), 0)
 }
 
+func TestKeys(t *testing.T) {
+	m := orderedmap.New[int, int]()
+	m.Set(3, 0)
+	m.Set(1, 0)
+	m.Set(2, 0)
+
+	keys := m.Keys()
+	sort.Ints(keys)
+	keys = append(keys, 9)
+	keys[0] = 100
+	gotwant.Test(t, m.Keys(), []int{3, 1, 2})
+
+	m.Delete(1)
+	m.Set(4, 0)
+	gotwant.Test(t, m.Keys(), []int{3, 2, 4})
+	gotwant.Test(t, m.IndexOf(4), 2)
+
+	buf := make([]int, 0, 8)
+	buf = m.AppendKeys(buf)
+	gotwant.Test(t, buf, []int{3, 2, 4})
+	buf = m.AppendKeys(buf[:1])
+	gotwant.Test(t, buf, []int{3, 3, 2, 4})
+
+	buf[1] = 100
+	gotwant.Test(t, m.Keys(), []int{3, 2, 4})
+
+	var nilm *orderedmap.OrderedMap[int, int]
+	gotwant.Test(t, nilm.AppendKeys([]int{1}), []int{1})
+}
+
 func TestNil(t *testing.T) {
 	var std map[string]int
 	var m *orderedmap.OrderedMap[string, int]
@@ -1070,6 +1153,99 @@ func BenchmarkDelete(b *testing.B) {
 	})
 }
 
+func BenchmarkDeleteExisting(b *testing.B) {
+	const count = 100000
+
+	b.Run("OM", func(b *testing.B) {
+		m := orderedmap.New[int, int]()
+		keys := rand.Perm(count)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if i%count == 0 {
+				b.StopTimer()
+				for _, k := range keys {
+					m.Set(k, k)
+				}
+				b.StartTimer()
+			}
+			m.Delete(keys[(i*7919)%count])
+		}
+	})
+}
+
+func BenchmarkIndexOf(b *testing.B) {
+	const count = 100000
+
+	m := orderedmap.New[int, int]()
+	for i := 0; i < count; i++ {
+		m.Set(i, i)
+	}
+	for i := 0; i < count; i += 3 {
+		m.Delete(i)
+	}
+
+	b.Run("OM", func(b *testing.B) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = m.IndexOf(i % count)
+		}
+	})
+}
+
+func BenchmarkAt(b *testing.B) {
+	const count = 100000
+
+	m := orderedmap.New[int, int]()
+	for i := 0; i < count; i++ {
+		m.Set(i, i)
+	}
+	for i := 0; i < count; i += 3 {
+		m.Delete(i)
+	}
+
+	b.Run("OM", func(b *testing.B) {
+		n := m.Len()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _, _ = m.At(i % n)
+		}
+	})
+}
+
+func BenchmarkMoveTo(b *testing.B) {
+	const count = 100000
+
+	fresh := func() *orderedmap.OrderedMap[int, int] {
+		m := orderedmap.New[int, int]()
+		for i := 0; i < count; i++ {
+			m.Set(i, i)
+		}
+		return m
+	}
+
+	b.Run("ToBack", func(b *testing.B) {
+		m := fresh()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.MoveToBack(i % count)
+		}
+	})
+	b.Run("ToFront", func(b *testing.B) {
+		m := fresh()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.MoveToFront(count - 1 - i%count)
+		}
+	})
+	b.Run("ToMiddle", func(b *testing.B) {
+		m := fresh()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.MoveTo(i%count, count/2)
+		}
+	})
+}
+
 func BenchmarkKeys(b *testing.B) {
 	std := make(map[string]int)
 	m := orderedmap.New[string, int]()
@@ -0,0 +1,196 @@
+package orderedmap
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// MarshalProperties encodes m as a Java .properties file of key=value lines, in order.
+// Characters other than printable ASCII are written as \uXXXX.
+func (m *OrderedMap[K, V]) MarshalProperties() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if m == nil {
+		return buf.Bytes(), nil
+	}
+
+	err := m.eachNote(func(lines []string, key, value any) error {
+		k, err := formatKey(key)
+		if err != nil {
+			return err
+		}
+		v, ok, err := scalarText(value)
+		if err != nil {
+			return err
+		}
+		if !ok && value != nil {
+			return fmt.Errorf("properties: unsupported type %T", value)
+		}
+
+		writeLines(buf, lines)
+		writePropertiesText(buf, k, true)
+		buf.WriteByte('=')
+		writePropertiesText(buf, v, false)
+		buf.WriteByte('\n')
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	writeLines(buf, m.trailer)
+	return buf.Bytes(), nil
+}
+
+func writePropertiesText(buf *bytes.Buffer, s string, key bool) {
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\f':
+			buf.WriteString(`\f`)
+		case r == ' ' && (key || i == 0):
+			buf.WriteString(`\ `)
+		case strings.ContainsRune("=:#!", r) && (key || i == 0):
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(buf, `\u%04X`, u)
+			}
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
+
+// UnmarshalProperties decodes a Java .properties file into m.
+//
+// Keys and values are separated by =, : or whitespaces.
+// Lines ending with a backslash are continued, and escapes including \uXXXX are decoded.
+func (m *OrderedMap[K, V]) UnmarshalProperties(b []byte) error {
+	defer m.beginDecode()()
+
+	lines := textLines(b)
+	var pending []string
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			pending = append(pending, lines[i])
+			continue
+		}
+		lineno := i + 1
+
+		// continued lines
+		for endsWithBackslash(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if endsWithBackslash(line) {
+			line = line[:len(line)-1]
+		}
+
+		// key
+		end := 0
+		for end < len(line) && !strings.ContainsRune("=: \t\f", rune(line[end])) {
+			if line[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end > len(line) {
+			end = len(line)
+		}
+		key := line[:end]
+
+		// separator
+		rest := strings.TrimLeft(line[end:], " \t\f")
+		if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+			rest = strings.TrimLeft(rest[1:], " \t\f")
+		}
+
+		k, err := unescapeProperties(key)
+		if err != nil {
+			return fmt.Errorf("properties: line %d: %w", lineno, err)
+		}
+		v, err := unescapeProperties(rest)
+		if err != nil {
+			return fmt.Errorf("properties: line %d: %w", lineno, err)
+		}
+
+		if err := m.setText(k, v, pending); err != nil {
+			return fmt.Errorf("properties: line %d: %w", lineno, err)
+		}
+		pending = nil
+	}
+
+	m.setTrailer(pending)
+	return nil
+}
+
+func endsWithBackslash(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func unescapeProperties(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var units []uint16 // pending \uXXXX, for surrogate pairs
+	flush := func(sb *strings.Builder) {
+		sb.WriteString(string(utf16.Decode(units)))
+		units = units[:0]
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && s[i+1] == 'u' {
+			if i+6 > len(s) {
+				return "", fmt.Errorf("invalid escape %q", s[i:])
+			}
+			u, err := strconv.ParseUint(s[i+2:i+6], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape %q", s[i:i+6])
+			}
+			units = append(units, uint16(u))
+			i += 5
+			continue
+		}
+		flush(&sb)
+
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	flush(&sb)
+
+	return sb.String(), nil
+}
@@ -0,0 +1,57 @@
+package orderedmap_test
+
+import (
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestUnmarshalProperties(t *testing.T) {
+	m := orderedmap.New[string, string](orderedmap.WithComments(true))
+	err := m.UnmarshalProperties([]byte(`# app
+! legacy comment
+app.name = My App
+app.greeting: hello \
+    world
+key\ with\ spaces value
+unicode=\u3042\uD83D\uDE00
+empty
+
+path=c:\\temp\ttab
+`))
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m.Keys(), []string{"app.name", "app.greeting", "key with spaces", "unicode", "empty", "path"})
+	gotwant.Test(t, m.Values(), []string{"My App", "hello world", "value", "あ😀", "", "c:\\temp\ttab"})
+	gotwant.Test(t, m.Comments("app.name"), []string{"# app", "! legacy comment"})
+	gotwant.Test(t, m.Comments("path"), []string{""})
+
+	gotwant.TestError(t, m.UnmarshalProperties([]byte(`a=\u12`)), "invalid escape")
+}
+
+func TestMarshalProperties(t *testing.T) {
+	m := orderedmap.New[string, any](orderedmap.WithComments(true))
+	m.Set("b", " leading space")
+	m.Set("a key", "x=y:z")
+	m.Set("unicode", "あ😀")
+	m.Set("n", 1.5)
+	m.SetComments("a key", []string{"# comment"})
+
+	b, err := m.MarshalProperties()
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b), `b=\ leading space
+# comment
+a\ key=x=y:z
+unicode=\u3042\uD83D\uDE00
+n=1.5
+`)
+
+	m2 := orderedmap.New[string, string](orderedmap.WithComments(true))
+	err = m2.UnmarshalProperties(b)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m2.Values(), []string{" leading space", "x=y:z", "あ😀", "1.5"})
+
+	b2, err := m2.MarshalProperties()
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b2), string(b))
+}
@@ -0,0 +1,81 @@
+//go:build go1.21
+
+package orderedmap
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// LogValue implements slog.LogValuer, as a group of the entries in order.
+// Nested maps (including Go maps, in key order) are groups too.
+func (m *OrderedMap[K, V]) LogValue() slog.Value {
+	if m == nil {
+		return slog.GroupValue()
+	}
+
+	attrs := make([]slog.Attr, 0, len(m.m))
+	for e := m.first(); e != nil; e = e.next() {
+		attrs = append(attrs, slog.Attr{Key: logKey(e.key), Value: logValue(e.v)})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (m *BiOrderedMap[K, V]) LogValue() slog.Value {
+	return m.inner().LogValue()
+}
+
+func logKey(key any) string {
+	if s, err := formatKey(key); err == nil {
+		return s
+	}
+	return fmt.Sprint(key)
+}
+
+func logValue(v any) slog.Value {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map && !rv.IsNil() {
+		if p, err := plainValue(v); err == nil {
+			v = p
+		}
+	}
+
+	if lv, ok := v.(slog.LogValuer); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() != reflect.Pointer || !rv.IsNil() {
+			return lv.LogValue()
+		}
+	}
+	return slog.AnyValue(v)
+}
+
+// FromAttrs returns a map of attrs in order, and groups in them as nested maps.
+// Like slog handlers do, the attrs of a group with an empty key are inlined,
+// and empty attrs and groups are ignored.
+func FromAttrs(attrs []slog.Attr) *OrderedMap[string, any] {
+	m := New[string, any]()
+	setAttrs(m, attrs)
+	return m
+}
+
+func setAttrs(m *OrderedMap[string, any], attrs []slog.Attr) {
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+
+		if v.Kind() != slog.KindGroup {
+			if a.Key == "" && v.Any() == nil {
+				continue
+			}
+			m.Set(a.Key, v.Any())
+			continue
+		}
+
+		group := v.Group()
+		switch {
+		case len(group) == 0:
+		case a.Key == "":
+			setAttrs(m, group)
+		default:
+			m.Set(a.Key, FromAttrs(group))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+//go:build go1.21
+
+package orderedmap_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestLogValue(t *testing.T) {
+	m := orderedmap.New[string, any]()
+	m.Set("z", 1)
+	m.Set("a", "x y")
+	nested := orderedmap.New[int, bool]()
+	nested.Set(2, true)
+	nested.Set(1, false)
+	m.Set("n", nested)
+	m.Set("g", map[string]any{"b": 1, "a": 2})
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("req", "meta", m)
+	gotwant.Test(t, buf.String(), `level=INFO msg=req meta.z=1 meta.a="x y" meta.n.2=true meta.n.1=false meta.g.a=2 meta.g.b=1`+"\n")
+
+	buf.Reset()
+	logger = slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key != "meta" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("req", "meta", m)
+	gotwant.Test(t, buf.String(), `{"meta":{"z":1,"a":"x y","n":{"2":true,"1":false},"g":{"a":2,"b":1}}}`+"\n")
+
+	t.Run("Bi", func(t *testing.T) {
+		bi := orderedmap.NewBi[string, int]()
+		bi.Set("b", 1)
+		bi.Set("a", 2)
+		gotwant.Test(t, bi.LogValue().String(), "[b=1 a=2]")
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		var m *orderedmap.OrderedMap[string, int]
+		gotwant.Test(t, len(m.LogValue().Group()), 0)
+	})
+}
+
+func TestFromAttrs(t *testing.T) {
+	m := orderedmap.FromAttrs([]slog.Attr{
+		slog.String("method", "GET"),
+		slog.Int("status", 200),
+		slog.Group("user", slog.String("name", "alice"), slog.Bool("admin", false)),
+		slog.Group("", slog.Duration("took", time.Second)),
+		slog.Group("empty"),
+		{},
+	})
+	gotwant.Test(t, m.Keys(), []string{"method", "status", "user", "took"})
+	gotwant.Test(t, m.GetDefault("status", nil), int64(200))
+	gotwant.Test(t, m.GetDefault("took", nil), time.Second)
+
+	user := m.GetDefault("user", nil).(*orderedmap.OrderedMap[string, any])
+	gotwant.Test(t, user.Keys(), []string{"name", "admin"})
+	gotwant.Test(t, user.Values(), []any{"alice", false})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		gotwant.Test(t, orderedmap.FromAttrs(m.LogValue().Group()).Keys(), m.Keys())
+	})
+}
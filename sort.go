@@ -0,0 +1,83 @@
+package orderedmap
+
+import "sort"
+
+func (m *OrderedMap[K, V]) Sort(less func(K, K) bool) {
+	if m == nil || m.cmp != nil {
+		return
+	}
+	before := m.snapshot()
+
+	order := m.entries()
+	sort.Slice(order, func(i, j int) bool {
+		return less(order[i].key, order[j].key)
+	})
+	m.rebuild(order)
+	m.changedWhole(EventSort, before)
+}
+
+// SortStableFunc sorts the keys by cmp, keeping the order of equal keys.
+func (m *OrderedMap[K, V]) SortStableFunc(cmp func(a, b K) int) {
+	m.sortEntries(func(a, b *elem[K, V]) int {
+		return cmp(a.key, b.key)
+	})
+}
+
+// SortByValue sorts the entries by their values, keeping the order of equal values.
+func (m *OrderedMap[K, V]) SortByValue(cmp func(a, b V) int) {
+	m.sortEntries(func(a, b *elem[K, V]) int {
+		return cmp(a.v, b.v)
+	})
+}
+
+// SortEntries sorts the entries by both keys and values, keeping the order of equal entries.
+func (m *OrderedMap[K, V]) SortEntries(cmp func(ak K, av V, bk K, bv V) int) {
+	m.sortEntries(func(a, b *elem[K, V]) int {
+		return cmp(a.key, a.v, b.key, b.v)
+	})
+}
+
+// Reverse reverses the order of the keys.
+func (m *OrderedMap[K, V]) Reverse() {
+	if m == nil || m.cmp != nil {
+		return
+	}
+	before := m.snapshot()
+
+	order := m.entries()
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	m.rebuild(order)
+	m.changedWhole(EventSort, before)
+}
+
+// IsSorted reports whether the keys are sorted by cmp.
+func (m *OrderedMap[K, V]) IsSorted(cmp func(a, b K) int) bool {
+	if m == nil {
+		return true
+	}
+
+	var prev *elem[K, V]
+	for e := m.first(); e != nil; e = e.next() {
+		if prev != nil && cmp(prev.key, e.key) > 0 {
+			return false
+		}
+		prev = e
+	}
+	return true
+}
+
+func (m *OrderedMap[K, V]) sortEntries(cmp func(a, b *elem[K, V]) int) {
+	if m == nil || m.cmp != nil {
+		return
+	}
+	before := m.snapshot()
+
+	order := m.entries()
+	sort.SliceStable(order, func(i, j int) bool {
+		return cmp(order[i], order[j]) < 0
+	})
+	m.rebuild(order)
+	m.changedWhole(EventSort, before)
+}
@@ -0,0 +1,112 @@
+package orderedmap_test
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestSortStableFunc(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	m.Set("b2", 0)
+	m.Set("a1", 0)
+	m.Set("b1", 0)
+	m.Set("a2", 0)
+
+	// compare only the first letter
+	m.SortStableFunc(func(a, b string) int {
+		return strings.Compare(a[:1], b[:1])
+	})
+	gotwant.Test(t, m.Keys(), []string{"a1", "a2", "b2", "b1"})
+
+	m.Delete("a2")
+	m.Set("c", 0)
+	gotwant.Test(t, m.Keys(), []string{"a1", "b2", "b1", "c"})
+}
+
+func TestSortByValue(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	m.Set("a", 3)
+	m.Set("b", 1)
+	m.Set("c", 2)
+	m.Set("d", 1)
+
+	m.SortByValue(func(a, b int) int {
+		return a - b
+	})
+	gotwant.Test(t, m.Keys(), []string{"b", "d", "c", "a"})
+	gotwant.Test(t, m.GetDefault("a", 0), 3)
+
+	m.Delete("d")
+	gotwant.Test(t, m.Keys(), []string{"b", "c", "a"})
+}
+
+func TestSortEntries(t *testing.T) {
+	m := orderedmap.New[string, int]()
+	m.Set("a", 2)
+	m.Set("b", 1)
+	m.Set("c", 2)
+	m.Set("d", 1)
+
+	// by value desc, then by key desc
+	m.SortEntries(func(ak string, av int, bk string, bv int) int {
+		if av != bv {
+			return bv - av
+		}
+		return strings.Compare(bk, ak)
+	})
+	gotwant.Test(t, m.Keys(), []string{"c", "a", "d", "b"})
+}
+
+func TestReverse(t *testing.T) {
+	m := orderedmap.New[int, int]()
+	m.Reverse()
+	gotwant.Test(t, m.Len(), 0)
+
+	for i := 0; i < 5; i++ {
+		m.Set(i, i)
+	}
+	m.Reverse()
+	gotwant.Test(t, m.Keys(), []int{4, 3, 2, 1, 0})
+
+	m.PreserveOrder(false)
+	m.Set(3, 3)
+	gotwant.Test(t, m.Keys(), []int{4, 2, 1, 0, 3})
+}
+
+func TestIsSorted(t *testing.T) {
+	cmp := func(a, b int) int { return a - b }
+
+	m := orderedmap.New[int, int]()
+	gotwant.Test(t, m.IsSorted(cmp), true)
+
+	m.Set(1, 0)
+	m.Set(3, 0)
+	m.Set(2, 0)
+	gotwant.Test(t, m.IsSorted(cmp), false)
+
+	m.SortStableFunc(cmp)
+	gotwant.Test(t, m.IsSorted(cmp), true)
+}
+
+func BenchmarkSortStableFunc(b *testing.B) {
+	b.Run("OM", func(b *testing.B) {
+		m := orderedmap.New[string, int]()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			for i := 0; i < 1000; i++ {
+				v := rand.Int()
+				k := strconv.Itoa(v)
+				m.Set(k, v)
+			}
+			b.StartTimer()
+
+			m.SortStableFunc(strings.Compare)
+		}
+	})
+}
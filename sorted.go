@@ -0,0 +1,91 @@
+package orderedmap
+
+// NewSorted returns a map whose keys are always sorted by cmp.
+//
+// Set inserts a new key at its sorted position and never moves an existing one,
+// and the reordering methods (Sort, Reverse, MoveTo, ...) do nothing.
+// Set, Delete and the queries (Range, Floor, Ceiling, Rank) are O(log n),
+// descending the tree the entries are kept in.
+func NewSorted[K comparable, V any](cmp func(a, b K) int, opts ...Option) *OrderedMap[K, V] {
+	m := New[K, V](opts...)
+	m.cmp = cmp
+	return m
+}
+
+// Range returns the keys k where lo <= k < hi.
+// It returns nil if m is not sorted.
+func (m *OrderedMap[K, V]) Range(lo, hi K) []K {
+	if m == nil || m.cmp == nil {
+		return nil
+	}
+
+	from := m.search(lo, false)
+	to := m.search(hi, false)
+	if to <= from {
+		return nil
+	}
+
+	keys := make([]K, 0, to-from)
+	for e := m.at(from); len(keys) < to-from; e = e.next() {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Floor returns the greatest key less than or equal to key.
+// It returns false if there is no such key or m is not sorted.
+func (m *OrderedMap[K, V]) Floor(key K) (K, bool) {
+	var gnil K
+	if m == nil || m.cmp == nil {
+		return gnil, false
+	}
+
+	idx := m.search(key, true)
+	if idx == 0 {
+		return gnil, false
+	}
+	return m.at(idx - 1).key, true
+}
+
+// Ceiling returns the least key greater than or equal to key.
+// It returns false if there is no such key or m is not sorted.
+func (m *OrderedMap[K, V]) Ceiling(key K) (K, bool) {
+	var gnil K
+	if m == nil || m.cmp == nil {
+		return gnil, false
+	}
+
+	idx := m.search(key, false)
+	if idx == len(m.m) {
+		return gnil, false
+	}
+	return m.at(idx).key, true
+}
+
+// Rank returns the number of keys less than key, or -1 if m is not sorted.
+func (m *OrderedMap[K, V]) Rank(key K) int {
+	if m == nil {
+		return 0
+	}
+	if m.cmp == nil {
+		return -1
+	}
+
+	return m.search(key, false)
+}
+
+// search returns the position of the first key that is greater than key,
+// or not less than key unless after, descending the tree in O(log n).
+func (m *OrderedMap[K, V]) search(key K, after bool) int {
+	i := 0
+	for e := m.root; e != nil; {
+		c := m.cmp(e.key, key)
+		if c < 0 || after && c == 0 {
+			i += e.left.count() + 1
+			e = e.right
+		} else {
+			e = e.left
+		}
+	}
+	return i
+}
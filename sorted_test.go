@@ -0,0 +1,160 @@
+package orderedmap_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func intcmp(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func TestSorted(t *testing.T) {
+	m := orderedmap.NewSorted[int, string](intcmp)
+	m.Set(5, "go")
+	m.Set(1, "ichi")
+	m.Set(9, "ku")
+	m.Set(3, "san")
+	gotwant.Test(t, m.Keys(), []int{1, 3, 5, 9})
+
+	m.PreserveOrder(false)
+	m.Set(1, "one")
+	gotwant.Test(t, m.Keys(), []int{1, 3, 5, 9})
+	gotwant.Test(t, m.GetDefault(1, ""), "one")
+
+	m.Delete(5)
+	gotwant.Test(t, m.Keys(), []int{1, 3, 9})
+
+	m.Reverse()
+	m.Sort(func(a, b int) bool { return a > b })
+	gotwant.Test(t, m.Keys(), []int{1, 3, 9})
+
+	b, err := json.Marshal(m)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b), `{"1":"one","3":"san","9":"ku"}`)
+
+	err = json.Unmarshal([]byte(`{"7":"nana","2":"ni"}`), m)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m.Keys(), []int{2, 7})
+	m.Set(4, "yon")
+	gotwant.Test(t, m.Keys(), []int{2, 4, 7})
+}
+
+func TestSortedRandom(t *testing.T) {
+	m := orderedmap.NewSorted[string, int](strings.Compare)
+	u := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		k := string(rune('a' + rand.Intn(26)))
+		if rand.Intn(3) == 0 {
+			m.Delete(k)
+			delete(u, k)
+		} else {
+			m.Set(k, i)
+			u[k] = i
+		}
+	}
+
+	keys := []string{}
+	for k := range u {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	gotwant.Test(t, append([]string{}, m.Keys()...), keys)
+}
+
+func TestRange(t *testing.T) {
+	m := orderedmap.NewSorted[int, int](intcmp)
+	for _, k := range []int{50, 10, 40, 20, 30} {
+		m.Set(k, k)
+	}
+
+	gotwant.Test(t, m.Range(20, 40), []int{20, 30})
+	gotwant.Test(t, m.Range(15, 45), []int{20, 30, 40})
+	gotwant.Test(t, m.Range(0, 100), []int{10, 20, 30, 40, 50})
+	gotwant.Test(t, len(m.Range(41, 45)), 0)
+	gotwant.Test(t, len(m.Range(40, 20)), 0)
+
+	// the result is a copy
+	r := m.Range(10, 30)
+	r[0] = 999
+	gotwant.Test(t, m.Keys(), []int{10, 20, 30, 40, 50})
+}
+
+func TestFloorCeilingRank(t *testing.T) {
+	m := orderedmap.NewSorted[int, int](intcmp)
+	for _, k := range []int{50, 10, 40, 20, 30} {
+		m.Set(k, k)
+	}
+
+	k, found := m.Floor(25)
+	gotwant.Test(t, found, true)
+	gotwant.Test(t, k, 20)
+	k, found = m.Floor(30)
+	gotwant.Test(t, found, true)
+	gotwant.Test(t, k, 30)
+	_, found = m.Floor(5)
+	gotwant.Test(t, found, false)
+
+	k, found = m.Ceiling(25)
+	gotwant.Test(t, found, true)
+	gotwant.Test(t, k, 30)
+	k, found = m.Ceiling(30)
+	gotwant.Test(t, found, true)
+	gotwant.Test(t, k, 30)
+	_, found = m.Ceiling(55)
+	gotwant.Test(t, found, false)
+
+	gotwant.Test(t, m.Rank(5), 0)
+	gotwant.Test(t, m.Rank(10), 0)
+	gotwant.Test(t, m.Rank(25), 2)
+	gotwant.Test(t, m.Rank(60), 5)
+
+	// not sorted
+	u := orderedmap.New[int, int]()
+	u.Set(1, 1)
+	gotwant.Test(t, u.Rank(1), -1)
+	gotwant.Test(t, u.Range(0, 2), []int(nil))
+	_, found = u.Floor(1)
+	gotwant.Test(t, found, false)
+	_, found = u.Ceiling(1)
+	gotwant.Test(t, found, false)
+}
+
+func TestSortedFuncs(t *testing.T) {
+	m := orderedmap.NewSorted[int, int](intcmp)
+	for _, k := range []int{5, 1, 4, 2, 3} {
+		m.Set(k, k)
+	}
+
+	f := orderedmap.Filter(m, func(k, v int) bool { return k%2 == 1 })
+	f.Set(2, 2)
+	gotwant.Test(t, f.Keys(), []int{1, 2, 3, 5})
+}
+
+func BenchmarkSortedSet(b *testing.B) {
+	const count = 100000
+
+	b.Run("Descending", func(b *testing.B) {
+		m := orderedmap.NewSorted[int, int](intcmp)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if i%count == 0 {
+				m.Clear()
+			}
+			k := count - i%count
+			m.Set(k, k)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package orderedmap
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// Value implements driver.Valuer, so that m can be stored in a JSON or text column.
+// A nil m is stored as NULL.
+func (m *OrderedMap[K, V]) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	b, err := m.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner, decoding a JSON column of []byte or string by UnmarshalJSON.
+// NULL leaves m empty.
+func (m *OrderedMap[K, V]) Scan(src any) error {
+	if m == nil {
+		return errors.New("orderedmap: Scan on nil map")
+	}
+
+	switch s := src.(type) {
+	case nil:
+		return m.UnmarshalJSON([]byte("{}"))
+	case []byte:
+		return m.UnmarshalJSON(s)
+	case string:
+		return m.UnmarshalJSON([]byte(s))
+	}
+	return fmt.Errorf("orderedmap: can not scan %T", src)
+}
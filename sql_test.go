@@ -0,0 +1,119 @@
+package orderedmap_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+// fakeDB is a database/sql driver of one column, keeping inserted values as they are.
+// "SELECT BYTES" returns strings as []byte, like Postgres drivers do.
+type fakeDB struct {
+	values []driver.Value
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return db, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+func (db *fakeDB) Close() error                                 { return nil }
+func (db *fakeDB) Begin() (driver.Tx, error)                    { return nil, errors.New("not supported") }
+
+func (db *fakeDB) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: db, query: query}, nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return strings.Count(s.query, "?") }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.values = append(s.db.values, args...)
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows := &fakeRows{}
+	for _, v := range s.db.values {
+		if str, ok := v.(string); ok && strings.Contains(s.query, "BYTES") {
+			v = []byte(str)
+		}
+		rows.values = append(rows.values, v)
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	values []driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"doc"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0] = r.values[0]
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestSQL(t *testing.T) {
+	fake := &fakeDB{}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	m := orderedmap.New[string, any]()
+	m.Set("z", 1.0)
+	m.Set("a", "x")
+	nested := orderedmap.New[string, any]()
+	nested.Set("y", true)
+	m.Set("b", nested)
+
+	_, err := db.Exec("INSERT INTO docs VALUES (?)", m)
+	gotwant.TestError(t, err, nil)
+	_, err = db.Exec("INSERT INTO docs VALUES (?)", (*orderedmap.OrderedMap[string, any])(nil))
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, fake.values, []driver.Value{`{"z":1,"a":"x","b":{"y":true}}`, nil})
+
+	for _, query := range []string{"SELECT doc FROM docs", "SELECT BYTES doc FROM docs"} {
+		t.Run(query, func(t *testing.T) {
+			rows, err := db.Query(query)
+			gotwant.TestError(t, err, nil)
+			defer rows.Close()
+
+			var got []*orderedmap.OrderedMap[string, any]
+			for rows.Next() {
+				m := orderedmap.New[string, any]()
+				m.Set("stale", 1)
+				gotwant.TestError(t, rows.Scan(m), nil)
+				got = append(got, m)
+			}
+			gotwant.TestError(t, rows.Err(), nil)
+
+			gotwant.Test(t, len(got), 2)
+			gotwant.Test(t, got[0].Keys(), []string{"z", "a", "b"})
+			gotwant.Test(t, got[0].GetDefault("b", nil), map[string]any{"y": true})
+			gotwant.Test(t, got[1].Len(), 0) // NULL
+		})
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		m := orderedmap.New[string, int]()
+		gotwant.TestError(t, m.Scan(1), "can not scan int")
+		gotwant.TestError(t, m.Scan(`{"a":"x"}`), "cannot unmarshal")
+
+		var nilMap *orderedmap.OrderedMap[string, int]
+		gotwant.TestError(t, nilMap.Scan("{}"), "nil map")
+	})
+}
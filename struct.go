@@ -0,0 +1,306 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FromStruct returns the fields of the struct v (or a pointer to it) as a map, in declaration order.
+//
+// Fields are selected and named like encoding/json does:
+// json tags rename fields, "-" skips them, omitempty skips empty values,
+// and the fields of embedded structs are promoted.
+// Values are set as they are.
+func FromStruct(v any) (*OrderedMap[string, any], error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("orderedmap: FromStruct(nil %v)", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("orderedmap: FromStruct(%T) is not a struct", v)
+	}
+
+	m := New[string, any]()
+	for _, f := range structFields(rv.Type()) {
+		fv, ok := fieldByIndex(rv, f.index, false)
+		if !ok {
+			continue // in a nil embedded pointer
+		}
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		m.Set(f.name, fv.Interface())
+	}
+	return m, nil
+}
+
+// StructKeysError reports the keys ToStruct could not match with fields.
+// The matched keys are assigned even if it is returned.
+type StructKeysError struct {
+	Unknown []string // keys without fields
+	Missing []string // fields (without omitempty) without keys
+}
+
+func (e *StructKeysError) Error() string {
+	var parts []string
+	if len(e.Unknown) != 0 {
+		parts = append(parts, "unknown keys "+strings.Join(e.Unknown, ", "))
+	}
+	if len(e.Missing) != 0 {
+		parts = append(parts, "missing keys "+strings.Join(e.Missing, ", "))
+	}
+	return "orderedmap: " + strings.Join(parts, "; ")
+}
+
+// ToStruct assigns the values of m to the fields of the struct dst points to, by key.
+// Fields are named like FromStruct does, and keys are matched case-insensitively if not exactly.
+//
+// Values are converted if they are not assignable:
+// between numbers, from strings into numbers, bools and encoding.TextUnmarshaler,
+// otherwise through encoding/json (e.g. *OrderedMap into a nested struct).
+//
+// If some keys are unknown or missing, ToStruct returns *StructKeysError after assigning the others.
+func ToStruct[V any](m *OrderedMap[string, V], dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("orderedmap: ToStruct(%T) is not a pointer to a struct", dst)
+	}
+	rv = rv.Elem()
+
+	fields := structFields(rv.Type())
+	assigned := make([]bool, len(fields))
+	var unknown []string
+
+	if m != nil {
+		for e := m.first(); e != nil; e = e.next() {
+			i := lookupField(fields, e.key)
+			if i < 0 {
+				unknown = append(unknown, e.key)
+				continue
+			}
+
+			fv, ok := fieldByIndex(rv, fields[i].index, true)
+			if !ok {
+				return fmt.Errorf("orderedmap: %s: can not set embedded pointer to unexported struct", e.key)
+			}
+			if err := assignValue(fv, e.v); err != nil {
+				return fmt.Errorf("orderedmap: %s: %w", e.key, err)
+			}
+			assigned[i] = true
+		}
+	}
+
+	var missing []string
+	for i, f := range fields {
+		if !assigned[i] && !f.omitEmpty {
+			missing = append(missing, f.name)
+		}
+	}
+
+	if len(unknown) != 0 || len(missing) != 0 {
+		return &StructKeysError{Unknown: unknown, Missing: missing}
+	}
+	return nil
+}
+
+type structField struct {
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+}
+
+// structFields returns the fields of t in declaration order, as encoding/json selects them.
+func structFields(t reflect.Type) []structField {
+	var all []structField
+	collectFields(t, nil, &all)
+
+	// resolve names by the rules of encoding/json:
+	// the shallowest wins, then the tagged one, otherwise none.
+	byName := make(map[string][]int)
+	for i, f := range all {
+		byName[f.name] = append(byName[f.name], i)
+	}
+
+	var fields []structField
+	for i, f := range all {
+		dominant := -1
+		ambiguous := false
+		for _, j := range byName[f.name] {
+			switch {
+			case dominant < 0:
+				dominant = j
+			case len(all[j].index) < len(all[dominant].index),
+				len(all[j].index) == len(all[dominant].index) && all[j].tagged && !all[dominant].tagged:
+				dominant, ambiguous = j, false
+			case len(all[j].index) == len(all[dominant].index) && all[j].tagged == all[dominant].tagged:
+				ambiguous = true
+			}
+		}
+		if dominant == i && !ambiguous {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func collectFields(t reflect.Type, index []int, fields *[]structField) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			collectFields(ft, appendIndex(index, i), fields)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		f := structField{
+			name:   sf.Name,
+			index:  appendIndex(index, i),
+			tagged: name != "",
+		}
+		if name != "" {
+			f.name = name
+		}
+		for _, opt := range strings.Split(opts, ",") {
+			if opt == "omitempty" {
+				f.omitEmpty = true
+			}
+		}
+		*fields = append(*fields, f)
+	}
+}
+
+func appendIndex(index []int, i int) []int {
+	return append(append([]int(nil), index...), i)
+}
+
+// lookupField returns the index of the field named key, or -1.
+func lookupField(fields []structField, key string) int {
+	for i, f := range fields {
+		if f.name == key {
+			return i
+		}
+	}
+	for i, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// fieldByIndex is v.FieldByIndex, but allocates nil embedded pointers if alloc,
+// otherwise returns false on them.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// assignValue sets x to the field fv, converting it if needed.
+func assignValue(fv reflect.Value, x any) error {
+	if !fv.CanSet() {
+		return fmt.Errorf("can not set %v", fv.Type())
+	}
+
+	xv := reflect.ValueOf(x)
+	if !xv.IsValid() {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+	if xv.Type().AssignableTo(fv.Type()) {
+		fv.Set(xv)
+		return nil
+	}
+
+	switch {
+	case isNumberKind(xv.Kind()) && isNumberKind(fv.Kind()):
+		c := xv.Convert(fv.Type())
+		if c.Convert(xv.Type()).Interface() != xv.Interface() || isNegative(c) != isNegative(xv) {
+			return fmt.Errorf("%v does not fit in %v", x, fv.Type())
+		}
+		fv.Set(c)
+		return nil
+
+	case xv.Kind() == reflect.String && fv.Kind() == xv.Kind():
+		fv.Set(xv.Convert(fv.Type()))
+		return nil
+
+	case xv.Kind() == reflect.String:
+		if err := parseTextValue(fv, xv.String()); err == nil {
+			return nil
+		}
+	}
+
+	// e.g. *OrderedMap into a struct, []any into []int
+	b, err := json.Marshal(x)
+	if err != nil {
+		return err
+	}
+	ptr := reflect.New(fv.Type())
+	if err := json.Unmarshal(b, ptr.Interface()); err != nil {
+		return err
+	}
+	fv.Set(ptr.Elem())
+	return nil
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return reflect.Int <= k && k <= reflect.Float64
+}
+
+func isNegative(v reflect.Value) bool {
+	switch {
+	case v.CanInt():
+		return v.Int() < 0
+	case v.CanFloat():
+		return v.Float() < 0
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package orderedmap_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+type structBase struct {
+	ID      int `json:"id"`
+	Version int `json:"version"`
+}
+
+type structAddr struct {
+	City string `json:"city"`
+}
+
+type structUser struct {
+	Name string `json:"name"`
+	structBase
+	Email   string     `json:"email,omitempty"`
+	Secret  string     `json:"-"`
+	Addr    structAddr `json:"addr"`
+	Version string     `json:"version"` // shadows structBase.Version
+	Created time.Time
+	private int
+}
+
+func TestFromStruct(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	u := structUser{
+		Name:       "alice",
+		structBase: structBase{ID: 1, Version: 9},
+		Secret:     "s",
+		Addr:       structAddr{"Tokyo"},
+		Version:    "v2",
+		Created:    created,
+	}
+
+	m, err := orderedmap.FromStruct(&u)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m.Keys(), []string{"name", "id", "addr", "version", "Created"})
+	gotwant.Test(t, m.Values(), []any{"alice", 1, structAddr{"Tokyo"}, "v2", created})
+
+	u.Email = "a@example.com"
+	m, err = orderedmap.FromStruct(u)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m.Keys(), []string{"name", "id", "email", "addr", "version", "Created"})
+
+	t.Run("EmbeddedPointer", func(t *testing.T) {
+		type s struct {
+			*structBase
+			Name string
+		}
+		m, err := orderedmap.FromStruct(s{Name: "a"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"Name"})
+
+		m, err = orderedmap.FromStruct(s{structBase: &structBase{ID: 2}, Name: "a"})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"id", "version", "Name"})
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := orderedmap.FromStruct(1)
+		gotwant.TestError(t, err, "not a struct")
+		_, err = orderedmap.FromStruct((*structUser)(nil))
+		gotwant.TestError(t, err, "nil")
+	})
+}
+
+func TestToStruct(t *testing.T) {
+	m := orderedmap.New[string, any]()
+	m.Set("name", "alice")
+	m.Set("id", int64(1))
+	m.Set("Email", "a@example.com") // case-insensitive
+	addr := orderedmap.New[string, any]()
+	addr.Set("city", "Tokyo")
+	m.Set("addr", addr)
+	m.Set("version", "v2")
+	m.Set("Created", "2024-01-02T03:04:05Z")
+
+	var u structUser
+	err := orderedmap.ToStruct(m, &u)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, u.Name, "alice")
+	gotwant.Test(t, u.ID, 1)
+	gotwant.Test(t, u.Email, "a@example.com")
+	gotwant.Test(t, u.Addr, structAddr{"Tokyo"})
+	gotwant.Test(t, u.Version, "v2")
+	gotwant.Test(t, u.Created, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		m, err := orderedmap.FromStruct(u)
+		gotwant.TestError(t, err, nil)
+
+		var u2 structUser
+		gotwant.TestError(t, orderedmap.ToStruct(m, &u2), nil)
+		gotwant.Test(t, u2, u)
+	})
+
+	t.Run("Conversion", func(t *testing.T) {
+		type s struct {
+			N  uint8
+			F  float32
+			B  bool
+			L  []int
+			D  time.Duration
+			P  *int
+			Pt *structAddr
+		}
+
+		m := orderedmap.New[string, any]()
+		m.Set("N", 200.0)
+		m.Set("F", 1)
+		m.Set("B", "true")
+		m.Set("L", []any{1, 2})
+		m.Set("D", int64(time.Second))
+		m.Set("P", nil)
+		m.Set("Pt", map[string]any{"city": "Osaka"})
+
+		var v s
+		gotwant.TestError(t, orderedmap.ToStruct(m, &v), nil)
+		gotwant.Test(t, v, s{N: 200, F: 1, B: true, L: []int{1, 2}, D: time.Second, Pt: &structAddr{"Osaka"}})
+
+		m.Set("N", 256)
+		gotwant.TestError(t, orderedmap.ToStruct(m, &v), "N: 256 does not fit in uint8")
+		m.Set("N", -1)
+		gotwant.TestError(t, orderedmap.ToStruct(m, &v), "does not fit")
+		m.Set("N", 1.5)
+		gotwant.TestError(t, orderedmap.ToStruct(m, &v), "does not fit")
+		m.Set("N", "x")
+		gotwant.TestError(t, orderedmap.ToStruct(m, &v), "N:")
+	})
+
+	t.Run("EmbeddedPointer", func(t *testing.T) {
+		type Base struct {
+			ID int `json:"id"`
+		}
+		type s struct {
+			*Base
+			Name string
+		}
+		m := orderedmap.New[string, int]()
+		m.Set("id", 3)
+		m.Set("Name", 5)
+
+		var v s
+		err := orderedmap.ToStruct(m, &v)
+		gotwant.TestError(t, err, `Name: json: cannot unmarshal number`)
+		gotwant.Test(t, *v.Base, Base{ID: 3})
+
+		var unexported struct {
+			*structBase
+		}
+		err = orderedmap.ToStruct(m, &unexported)
+		gotwant.TestError(t, err, "id: can not set embedded pointer to unexported struct")
+	})
+
+	t.Run("UnknownMissing", func(t *testing.T) {
+		m := orderedmap.New[string, string]()
+		m.Set("name", "bob")
+		m.Set("age", "20")
+		m.Set("Secret", "s")
+
+		var u structUser
+		err := orderedmap.ToStruct(m, &u)
+		gotwant.TestError(t, err, "unknown keys age, Secret; missing keys id, addr, version, Created")
+		gotwant.Test(t, u.Name, "bob")
+
+		var keysErr *orderedmap.StructKeysError
+		gotwant.Test(t, errors.As(err, &keysErr), true)
+		gotwant.Test(t, keysErr.Unknown, []string{"age", "Secret"})
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var u structUser
+		gotwant.TestError(t, orderedmap.ToStruct(m, u), "not a pointer to a struct")
+		gotwant.TestError(t, orderedmap.ToStruct(m, (*structUser)(nil)), "not a pointer to a struct")
+	})
+}
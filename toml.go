@@ -0,0 +1,1091 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MarshalTOML encodes m as a TOML document.
+//
+// Nested maps are written as [tables], and slices of maps as [[arrays of tables]].
+// Keys keep their order within each table,
+// except that TOML requires the plain key/value pairs of a table to precede its sub-tables.
+// nil values are omitted because TOML has no null.
+func (m *OrderedMap[K, V]) MarshalTOML() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if m == nil {
+		return buf.Bytes(), nil
+	}
+
+	if err := encodeTOMLTable(buf, nil, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalTOML decodes the TOML document b into m.
+//
+// Tables are decoded into *OrderedMap[string, any], arrays into []any,
+// integers into int64, floats into float64, offset date-times into time.Time,
+// and local dates, date-times and times into LocalDate, LocalDateTime and LocalTime,
+// then converted to V through encoding/json if V is not any.
+func (m *OrderedMap[K, V]) UnmarshalTOML(b []byte) error {
+	root, err := parseTOML(b)
+	if err != nil {
+		return err
+	}
+
+	defer m.beginDecode()()
+
+	for e := root.first(); e != nil; e = e.next() {
+		if err := m.setPlain(e.key, e.v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LocalDate is a date without a time zone, such as TOML 1979-05-27.
+type LocalDate struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+func (d LocalDate) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+func (d LocalDate) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *LocalDate) UnmarshalText(b []byte) error {
+	v, err := parseLocalDate(string(b))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// LocalTime is a time of day without a time zone, such as TOML 07:32:00.
+type LocalTime struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+func (t LocalTime) String() string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Nanosecond != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%09d", t.Nanosecond), "0")
+	}
+	return s
+}
+
+func (t LocalTime) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *LocalTime) UnmarshalText(b []byte) error {
+	v, err := parseLocalTime(string(b))
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
+
+// LocalDateTime is a date and a time without a time zone, such as TOML 1979-05-27T07:32:00.
+type LocalDateTime struct {
+	Date LocalDate
+	Time LocalTime
+}
+
+func (dt LocalDateTime) String() string {
+	return dt.Date.String() + "T" + dt.Time.String()
+}
+
+func (dt LocalDateTime) MarshalText() ([]byte, error) {
+	return []byte(dt.String()), nil
+}
+
+func (dt *LocalDateTime) UnmarshalText(b []byte) error {
+	v, err := parseTOMLDateTime(string(b))
+	if err != nil {
+		return err
+	}
+	x, ok := v.(LocalDateTime)
+	if !ok {
+		return fmt.Errorf("invalid local date-time %q", b)
+	}
+	*dt = x
+	return nil
+}
+
+type tomlPair struct {
+	key   string
+	value any
+}
+
+// tomlPairs returns the pairs of t with the plain values, dropping nils.
+func tomlPairs(t anyMap) ([]tomlPair, error) {
+	pairs := make([]tomlPair, 0, t.lenAny())
+	err := t.eachAny(func(key, value any) error {
+		k, err := formatKey(key)
+		if err != nil {
+			return err
+		}
+		v, err := tomlPlainValue(value)
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			pairs = append(pairs, tomlPair{k, v})
+		}
+		return nil
+	})
+	return pairs, err
+}
+
+// tomlPlainValue is plainValue, but keeps the local date-times,
+// which would otherwise be strings.
+func tomlPlainValue(v any) (any, error) {
+	switch x := v.(type) {
+	case LocalDate, LocalTime, LocalDateTime:
+		return x, nil
+	case []any:
+		list := make([]any, len(x))
+		for i, elem := range x {
+			p, err := tomlPlainValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = p
+		}
+		return list, nil
+	}
+	return plainValue(v)
+}
+
+func encodeTOMLTable(buf *bytes.Buffer, path []string, t anyMap) error {
+	pairs, err := tomlPairs(t)
+	if err != nil {
+		return err
+	}
+
+	// key = value
+	for _, p := range pairs {
+		if isTOMLTable(p.value) || isTOMLArrayOfTables(p.value) {
+			continue
+		}
+
+		writeTOMLKey(buf, p.key)
+		buf.WriteString(" = ")
+		if err := encodeTOMLValue(buf, p.value); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+	}
+
+	// [table], [[array]]
+	for _, p := range pairs {
+		sub := append(path[:len(path):len(path)], p.key)
+
+		if isTOMLTable(p.value) {
+			writeTOMLHeader(buf, "[", sub, "]")
+			if err := encodeTOMLTable(buf, sub, p.value.(anyMap)); err != nil {
+				return err
+			}
+
+		} else if isTOMLArrayOfTables(p.value) {
+			for _, elem := range p.value.([]any) {
+				writeTOMLHeader(buf, "[[", sub, "]]")
+				if err := encodeTOMLTable(buf, sub, elem.(anyMap)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func isTOMLTable(v any) bool {
+	_, ok := v.(anyMap)
+	return ok
+}
+
+func isTOMLArrayOfTables(v any) bool {
+	list, ok := v.([]any)
+	if !ok || len(list) == 0 {
+		return false
+	}
+	for _, elem := range list {
+		if !isTOMLTable(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+func writeTOMLHeader(buf *bytes.Buffer, open string, path []string, close string) {
+	if buf.Len() > 0 {
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(open)
+	for i, k := range path {
+		if i != 0 {
+			buf.WriteByte('.')
+		}
+		writeTOMLKey(buf, k)
+	}
+	buf.WriteString(close)
+	buf.WriteByte('\n')
+}
+
+func writeTOMLKey(buf *bytes.Buffer, key string) {
+	if key == "" {
+		buf.WriteString(`""`)
+		return
+	}
+	for i := 0; i < len(key); i++ {
+		if !isTOMLBareKeyChar(key[i]) {
+			writeTOMLString(buf, key)
+			return
+		}
+	}
+	buf.WriteString(key)
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+func writeTOMLString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\r':
+			buf.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(buf, `\u%04X`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// encodeTOMLValue writes a plain value inline.
+func encodeTOMLValue(buf *bytes.Buffer, v any) error {
+	switch x := v.(type) {
+	case bool:
+		buf.WriteString(strconv.FormatBool(x))
+	case string:
+		writeTOMLString(buf, x)
+	case []byte:
+		writeTOMLString(buf, base64.StdEncoding.EncodeToString(x))
+	case int64:
+		buf.WriteString(strconv.FormatInt(x, 10))
+	case uint64:
+		if x > math.MaxInt64 {
+			return fmt.Errorf("toml: integer %v overflows", x)
+		}
+		buf.WriteString(strconv.FormatUint(x, 10))
+	case float64:
+		switch {
+		case math.IsNaN(x):
+			buf.WriteString("nan")
+		case math.IsInf(x, 1):
+			buf.WriteString("inf")
+		case math.IsInf(x, -1):
+			buf.WriteString("-inf")
+		default:
+			s := strconv.FormatFloat(x, 'g', -1, 64)
+			if !strings.ContainsAny(s, ".eE") {
+				s += ".0"
+			}
+			buf.WriteString(s)
+		}
+	case time.Time:
+		buf.WriteString(x.Format(time.RFC3339Nano))
+	case LocalDate, LocalTime, LocalDateTime:
+		fmt.Fprint(buf, x)
+
+	case []any:
+		buf.WriteByte('[')
+		for i, elem := range x {
+			if i != 0 {
+				buf.WriteString(", ")
+			}
+			if elem == nil {
+				return errors.New("toml: nil in array")
+			}
+			if err := encodeTOMLValue(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+
+	case anyMap:
+		pairs, err := tomlPairs(x)
+		if err != nil {
+			return err
+		}
+
+		buf.WriteByte('{')
+		for i, p := range pairs {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte(' ')
+			writeTOMLKey(buf, p.key)
+			buf.WriteString(" = ")
+			if err := encodeTOMLValue(buf, p.value); err != nil {
+				return err
+			}
+		}
+		if len(pairs) != 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteByte('}')
+
+	default:
+		return fmt.Errorf("toml: unsupported type %T", v)
+	}
+	return nil
+}
+
+// tomlParser parses a TOML document into ordered maps.
+type tomlParser struct {
+	src  []byte
+	pos  int
+	line int
+
+	root    *OrderedMap[string, any]
+	current *OrderedMap[string, any]
+
+	defined map[*OrderedMap[string, any]]bool // by [table]
+	dotted  map[*OrderedMap[string, any]]bool // by a.b = v
+	inline  map[*OrderedMap[string, any]]bool // by { ... }, which can not be extended
+}
+
+func parseTOML(b []byte) (*OrderedMap[string, any], error) {
+	p := &tomlParser{
+		src:     b,
+		line:    1,
+		root:    New[string, any](),
+		defined: make(map[*OrderedMap[string, any]]bool),
+		dotted:  make(map[*OrderedMap[string, any]]bool),
+		inline:  make(map[*OrderedMap[string, any]]bool),
+	}
+	p.current = p.root
+
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("toml: line %d: %w", p.line, err)
+	}
+	return p.root, nil
+}
+
+func (p *tomlParser) parse() error {
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return nil
+		}
+
+		switch p.peek() {
+		case '#':
+			p.skipComment()
+		case '\r', '\n':
+			if err := p.newline(); err != nil {
+				return err
+			}
+			continue
+		case '[':
+			if err := p.parseHeader(); err != nil {
+				return err
+			}
+		default:
+			if err := p.parseKeyValue(p.current); err != nil {
+				return err
+			}
+		}
+
+		if err := p.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *tomlParser) parseHeader() error {
+	p.pos++ // [
+	array := !p.eof() && p.peek() == '['
+	if array {
+		p.pos++
+	}
+
+	p.skipSpaces()
+	path, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpaces()
+
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	if !bytes.HasPrefix(p.src[p.pos:], []byte(closing)) {
+		return fmt.Errorf("%s is required", closing)
+	}
+	p.pos += len(closing)
+
+	parent, err := p.descend(p.root, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+
+	if array {
+		existing, found := parent.Get(last)
+		list, ok := existing.([]any)
+		if found && (!ok || p.isStaticArray(list)) {
+			return fmt.Errorf("%q is already defined", last)
+		}
+
+		t := New[string, any]()
+		p.defined[t] = true
+		parent.Set(last, append(list, t))
+		p.current = t
+		return nil
+	}
+
+	existing, found := parent.Get(last)
+	if !found {
+		t := New[string, any]()
+		parent.Set(last, t)
+		existing = t
+	}
+	t, ok := existing.(*OrderedMap[string, any])
+	if !ok || p.defined[t] || p.dotted[t] || p.inline[t] {
+		return fmt.Errorf("%q is already defined", last)
+	}
+	p.defined[t] = true
+	p.current = t
+	return nil
+}
+
+// isStaticArray reports whether list is defined by key = [...], not by [[array]].
+func (p *tomlParser) isStaticArray(list []any) bool {
+	if len(list) == 0 {
+		return true
+	}
+	t, ok := list[0].(*OrderedMap[string, any])
+	return !ok || p.inline[t]
+}
+
+// descend returns the table at path from t, creating the missing tables.
+// The last table of an array of tables is followed.
+func (p *tomlParser) descend(t *OrderedMap[string, any], path []string) (*OrderedMap[string, any], error) {
+	for _, k := range path {
+		v, found := t.Get(k)
+		if !found {
+			sub := New[string, any]()
+			t.Set(k, sub)
+			t = sub
+			continue
+		}
+
+		switch x := v.(type) {
+		case *OrderedMap[string, any]:
+			if p.inline[x] {
+				return nil, fmt.Errorf("%q is an inline table", k)
+			}
+			t = x
+		case []any:
+			if p.isStaticArray(x) {
+				return nil, fmt.Errorf("%q is not a table", k)
+			}
+			t = x[len(x)-1].(*OrderedMap[string, any])
+		default:
+			return nil, fmt.Errorf("%q is not a table", k)
+		}
+	}
+	return t, nil
+}
+
+func (p *tomlParser) parseKeyValue(t *OrderedMap[string, any]) error {
+	path, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	p.skipSpaces()
+	if p.eof() || p.peek() != '=' {
+		return errors.New("= is required")
+	}
+	p.pos++
+	p.skipSpaces()
+
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	// a.b.c = v defines the tables a and a.b
+	for _, k := range path[:len(path)-1] {
+		existing, found := t.Get(k)
+		if !found {
+			sub := New[string, any]()
+			t.Set(k, sub)
+			existing = sub
+		}
+		sub, ok := existing.(*OrderedMap[string, any])
+		if !ok || p.inline[sub] || found && p.defined[sub] {
+			return fmt.Errorf("%q is already defined", k)
+		}
+		p.dotted[sub] = true
+		t = sub
+	}
+
+	last := path[len(path)-1]
+	if t.Contains(last) {
+		return fmt.Errorf("%q is already defined", last)
+	}
+	t.Set(last, v)
+	return nil
+}
+
+// parseKey parses a (dotted) key.
+func (p *tomlParser) parseKey() ([]string, error) {
+	var path []string
+	for {
+		if p.eof() {
+			return nil, errors.New("key is required")
+		}
+
+		var k string
+		var err error
+		switch p.peek() {
+		case '"':
+			k, err = p.parseBasicString()
+		case '\'':
+			k, err = p.parseLiteralString()
+		default:
+			start := p.pos
+			for !p.eof() && isTOMLBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, fmt.Errorf("unexpected %q", p.peek())
+			}
+			k = string(p.src[start:p.pos])
+		}
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, k)
+
+		p.skipSpaces()
+		if p.eof() || p.peek() != '.' {
+			return path, nil
+		}
+		p.pos++
+		p.skipSpaces()
+	}
+}
+
+func (p *tomlParser) parseValue() (any, error) {
+	if p.eof() {
+		return nil, errors.New("value is required")
+	}
+
+	switch c := p.peek(); {
+	case c == '"':
+		if bytes.HasPrefix(p.src[p.pos:], []byte(`"""`)) {
+			return p.parseMultilineBasicString()
+		}
+		return p.parseBasicString()
+	case c == '\'':
+		if bytes.HasPrefix(p.src[p.pos:], []byte(`'''`)) {
+			return p.parseMultilineLiteralString()
+		}
+		return p.parseLiteralString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	}
+
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) {
+		p.pos++
+	}
+	// a date-time may be separated by a space
+	if p.pos-start == 10 && p.pos+1 < len(p.src) && p.src[p.pos] == ' ' && isDigit(p.src[p.pos+1]) {
+		p.pos++
+		for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) {
+			p.pos++
+		}
+	}
+	return parseTOMLScalar(string(p.src[start:p.pos]))
+}
+
+func parseTOMLScalar(s string) (any, error) {
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	case "":
+		return nil, errors.New("value is required")
+	}
+
+	if len(s) >= 10 && isDigit(s[0]) && s[4] == '-' {
+		return parseTOMLDateTime(s)
+	}
+	if len(s) >= 3 && s[2] == ':' {
+		return parseLocalTime(s)
+	}
+
+	if strings.Contains(s, "__") || strings.HasPrefix(s, "_") || strings.HasSuffix(s, "_") {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	num := strings.ReplaceAll(s, "_", "")
+
+	if len(num) > 2 && num[0] == '0' && strings.ContainsRune("xob", rune(num[1])) {
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[num[1]]
+		n, err := strconv.ParseInt(num[2:], base, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		return n, nil
+	}
+
+	if !strings.ContainsAny(num, ".eE") {
+		digits := strings.TrimLeft(num, "+-")
+		if len(digits) > 1 && digits[0] == '0' {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		n, err := strconv.ParseInt(num, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		return n, nil
+	}
+
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return f, nil
+}
+
+// parseTOMLDateTime parses an offset date-time into time.Time,
+// or a local one into LocalDateTime or LocalDate.
+func parseTOMLDateTime(s string) (any, error) {
+	s = strings.Replace(s, " ", "T", 1)
+	if len(s) > 10 && (s[10] == 't') {
+		s = s[:10] + "T" + s[11:]
+	}
+	s = strings.Replace(s, "z", "Z", 1)
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04Z07:00"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	if len(s) < 10 {
+		return nil, fmt.Errorf("invalid date-time %q", s)
+	}
+	d, err := parseLocalDate(s[:10])
+	if err != nil {
+		return nil, fmt.Errorf("invalid date-time %q", s)
+	}
+	if len(s) == 10 {
+		return d, nil
+	}
+	if s[10] != 'T' {
+		return nil, fmt.Errorf("invalid date-time %q", s)
+	}
+	t, err := parseLocalTime(s[11:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid date-time %q", s)
+	}
+	return LocalDateTime{Date: d, Time: t}, nil
+}
+
+func parseLocalDate(s string) (LocalDate, error) {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return LocalDate{}, fmt.Errorf("invalid local date %q", s)
+	}
+	return LocalDate{Year: t.Year(), Month: t.Month(), Day: t.Day()}, nil
+}
+
+// parseLocalTime parses hh:mm:ss with optional fractions, or hh:mm.
+func parseLocalTime(s string) (LocalTime, error) {
+	if len(s) < 5 || s[2] != ':' {
+		return LocalTime{}, fmt.Errorf("invalid local time %q", s)
+	}
+	for _, layout := range []string{"15:04:05.999999999", "15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return LocalTime{Hour: t.Hour(), Minute: t.Minute(), Second: t.Second(), Nanosecond: t.Nanosecond()}, nil
+		}
+	}
+	return LocalTime{}, fmt.Errorf("invalid local time %q", s)
+}
+
+func (p *tomlParser) parseArray() (any, error) {
+	p.pos++ // [
+
+	list := []any{}
+	for {
+		if err := p.skipSpacesAndComments(); err != nil {
+			return nil, err
+		}
+		if p.eof() {
+			return nil, errors.New("] is required")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return list, nil
+		}
+
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+
+		if err := p.skipSpacesAndComments(); err != nil {
+			return nil, err
+		}
+		if p.eof() {
+			return nil, errors.New("] is required")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return list, nil
+		default:
+			return nil, fmt.Errorf("unexpected %q in array", p.peek())
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (any, error) {
+	p.pos++ // {
+
+	t := New[string, any]()
+	p.skipSpaces()
+	if !p.eof() && p.peek() == '}' {
+		p.pos++
+		p.inline[t] = true
+		return t, nil
+	}
+
+	for {
+		p.skipSpaces()
+		if err := p.parseKeyValue(t); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+
+		if p.eof() {
+			return nil, errors.New("} is required")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			p.inline[t] = true
+			return t, nil
+		default:
+			return nil, fmt.Errorf("unexpected %q in inline table", p.peek())
+		}
+	}
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.pos++ // "
+
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", errors.New(`" is required`)
+		}
+
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return sb.String(), nil
+		case '\n':
+			return "", errors.New("newline in string")
+		case '\\':
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *tomlParser) parseMultilineBasicString() (string, error) {
+	p.pos += 3 // """
+	p.trimFirstNewline()
+
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", errors.New(`""" is required`)
+		}
+
+		if bytes.HasPrefix(p.src[p.pos:], []byte(`"""`)) {
+			p.pos += 3
+			// up to 2 quotes are allowed just before the closing
+			for i := 0; i < 2 && !p.eof() && p.peek() == '"'; i++ {
+				sb.WriteByte('"')
+				p.pos++
+			}
+			return sb.String(), nil
+		}
+
+		c := p.peek()
+		switch {
+		case c == '\\' && p.isLineEndingBackslash():
+			// trim the newline and the following whitespaces
+			p.pos++
+			for !p.eof() && strings.ContainsRune(" \t\r\n", rune(p.peek())) {
+				if p.peek() == '\n' {
+					p.line++
+				}
+				p.pos++
+			}
+		case c == '\\':
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *tomlParser) isLineEndingBackslash() bool {
+	for i := p.pos + 1; i < len(p.src); i++ {
+		switch p.src[i] {
+		case ' ', '\t', '\r':
+		case '\n':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+func (p *tomlParser) parseEscape(sb *strings.Builder) error {
+	p.pos++ // \
+	if p.eof() {
+		return errors.New("invalid escape")
+	}
+
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'e':
+		sb.WriteByte(0x1b)
+	case '"':
+		sb.WriteByte('"')
+	case '\\':
+		sb.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.src) {
+			return errors.New("invalid escape")
+		}
+		r, err := strconv.ParseUint(string(p.src[p.pos:p.pos+n]), 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return errors.New("invalid escape")
+		}
+		sb.WriteRune(rune(r))
+		p.pos += n
+	default:
+		return fmt.Errorf("invalid escape \\%c", c)
+	}
+	return nil
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.pos++ // '
+
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		if p.peek() == '\n' {
+			return "", errors.New("newline in string")
+		}
+		p.pos++
+	}
+	if p.eof() {
+		return "", errors.New(`' is required`)
+	}
+	s := string(p.src[start:p.pos])
+	p.pos++
+	return s, nil
+}
+
+func (p *tomlParser) parseMultilineLiteralString() (string, error) {
+	p.pos += 3 // '''
+	p.trimFirstNewline()
+
+	end := bytes.Index(p.src[p.pos:], []byte(`'''`))
+	if end < 0 {
+		return "", errors.New(`''' is required`)
+	}
+	end += p.pos
+	// up to 2 quotes are allowed just before the closing
+	for i := 0; i < 2 && end+3 < len(p.src) && p.src[end+3] == '\''; i++ {
+		end++
+	}
+
+	s := string(p.src[p.pos:end])
+	p.line += strings.Count(s, "\n")
+	p.pos = end + 3
+	return s, nil
+}
+
+func (p *tomlParser) trimFirstNewline() {
+	if bytes.HasPrefix(p.src[p.pos:], []byte("\r\n")) {
+		p.pos += 2
+		p.line++
+	} else if bytes.HasPrefix(p.src[p.pos:], []byte("\n")) {
+		p.pos++
+		p.line++
+	}
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek() byte {
+	return p.src[p.pos]
+}
+
+func (p *tomlParser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *tomlParser) skipComment() {
+	for !p.eof() && p.peek() != '\n' && p.peek() != '\r' {
+		p.pos++
+	}
+}
+
+func (p *tomlParser) skipSpacesAndComments() error {
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return nil
+		}
+		switch p.peek() {
+		case '#':
+			p.skipComment()
+		case '\r', '\n':
+			if err := p.newline(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func (p *tomlParser) newline() error {
+	if p.peek() == '\r' {
+		p.pos++
+		if p.eof() || p.peek() != '\n' {
+			return errors.New("bare CR")
+		}
+	}
+	p.pos++
+	p.line++
+	return nil
+}
+
+// endOfLine consumes the rest of a line, allowing a comment.
+func (p *tomlParser) endOfLine() error {
+	p.skipSpaces()
+	if !p.eof() && p.peek() == '#' {
+		p.skipComment()
+	}
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\r' && p.peek() != '\n' {
+		return fmt.Errorf("unexpected %q", p.peek())
+	}
+	return p.newline()
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
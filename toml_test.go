@@ -0,0 +1,162 @@
+package orderedmap_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+const tomlDoc = `# config
+title = "TOML \"Example\""
+zeta = 1
+
+[server]
+port = 8080
+host = 'localhost'
+hosts = [
+  "a", # first
+  "b",
+]
+
+[server.tls]
+enabled = true
+
+[[users]]
+name = "bob"
+ratio = 0.5
+
+[[users]]
+name = "alice"
+ratio = 1e3
+
+[database]
+point = { y = 2, x = 1 }
+created = 1979-05-27T07:32:00Z
+`
+
+func TestUnmarshalTOML(t *testing.T) {
+	m := orderedmap.New[string, any]()
+	err := m.UnmarshalTOML([]byte(tomlDoc))
+	gotwant.TestError(t, err, nil)
+
+	gotwant.Test(t, m.Keys(), []string{"title", "zeta", "server", "users", "database"})
+	gotwant.Test(t, m.GetDefault("title", nil), `TOML "Example"`)
+	gotwant.Test(t, m.GetDefault("zeta", nil), int64(1))
+
+	server := m.GetDefault("server", nil).(*orderedmap.OrderedMap[string, any])
+	gotwant.Test(t, server.Keys(), []string{"port", "host", "hosts", "tls"})
+	gotwant.Test(t, server.GetDefault("hosts", nil), []any{"a", "b"})
+
+	users := m.GetDefault("users", nil).([]any)
+	gotwant.Test(t, len(users), 2)
+	gotwant.Test(t, users[1].(*orderedmap.OrderedMap[string, any]).GetDefault("name", nil), "alice")
+	gotwant.Test(t, users[1].(*orderedmap.OrderedMap[string, any]).GetDefault("ratio", nil), 1000.0)
+
+	database := m.GetDefault("database", nil).(*orderedmap.OrderedMap[string, any])
+	gotwant.Test(t, database.GetDefault("point", nil).(*orderedmap.OrderedMap[string, any]).Keys(), []string{"y", "x"})
+	gotwant.Test(t, database.GetDefault("created", nil).(time.Time).Equal(time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC)), true)
+
+	t.Run("Typed", func(t *testing.T) {
+		m := orderedmap.New[string, *orderedmap.OrderedMap[string, int]]()
+		err := m.UnmarshalTOML([]byte("[b]\nz = 1\ny = 2\n[a]\nx = 3\n"))
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"b", "a"})
+		gotwant.Test(t, m.GetDefault("b", nil).Keys(), []string{"z", "y"})
+		gotwant.Test(t, m.GetDefault("a", nil).GetDefault("x", 0), 3)
+	})
+
+	t.Run("Local", func(t *testing.T) {
+		local := time.Local
+		time.Local = time.FixedZone("JST", 9*60*60)
+		defer func() { time.Local = local }()
+
+		doc := `odt = 1979-05-27T07:32:00-07:00
+ldt = 1979-05-27T07:32:00.5
+ld = 1979-05-27
+lt = 07:32:00
+times = [00:32:00.999999, 07:32:00]
+`
+		m := orderedmap.New[string, any]()
+		err := m.UnmarshalTOML([]byte(doc))
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.GetDefault("ldt", nil), orderedmap.LocalDateTime{
+			Date: orderedmap.LocalDate{Year: 1979, Month: time.May, Day: 27},
+			Time: orderedmap.LocalTime{Hour: 7, Minute: 32, Nanosecond: 500000000},
+		})
+		gotwant.Test(t, m.GetDefault("ld", nil), orderedmap.LocalDate{Year: 1979, Month: time.May, Day: 27})
+		gotwant.Test(t, m.GetDefault("lt", nil), orderedmap.LocalTime{Hour: 7, Minute: 32})
+
+		b, err := m.MarshalTOML()
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, string(b), doc)
+
+		s := orderedmap.New[string, string]()
+		err = s.UnmarshalTOML([]byte(doc[:strings.Index(doc, "times")]))
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, s.Values()[1:], []string{"1979-05-27T07:32:00.5", "1979-05-27", "07:32:00"})
+
+		err = m.UnmarshalTOML([]byte("lt = 25:00:00"))
+		gotwant.TestError(t, err, "invalid local time")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		for _, doc := range []string{
+			"a = 1\na = 2",
+			"[a]\n[a]",
+			"a = 1\n[a]",
+			"a = \"unterminated",
+			"a = 01",
+			"a = {x = 1}\n[a]",
+		} {
+			err := orderedmap.New[string, any]().UnmarshalTOML([]byte(doc))
+			gotwant.Test(t, err != nil, true, gotwant.Desc(doc))
+		}
+	})
+}
+
+func TestMarshalTOML(t *testing.T) {
+	sub := orderedmap.New[string, any]()
+	sub.Set("port", 8080)
+	sub.Set("host", "localhost")
+
+	user1 := orderedmap.New[string, any]()
+	user1.Set("name", "bob")
+	user2 := orderedmap.New[string, any]()
+	user2.Set("name", "alice")
+	user2.Set("admin", true)
+
+	m := orderedmap.New[string, any]()
+	m.Set("zeta", 1.5)
+	m.Set("server", sub)
+	m.Set("users", []*orderedmap.OrderedMap[string, any]{user1, user2})
+	m.Set("key with space", []int{1, 2})
+	m.Set("nothing", nil)
+
+	b, err := m.MarshalTOML()
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b), `zeta = 1.5
+"key with space" = [1, 2]
+
+[server]
+port = 8080
+host = "localhost"
+
+[[users]]
+name = "bob"
+
+[[users]]
+name = "alice"
+admin = true
+`)
+
+	// round trip
+	m2 := orderedmap.New[string, any]()
+	err = m2.UnmarshalTOML(b)
+	gotwant.TestError(t, err, nil)
+	b2, err := m2.MarshalTOML()
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b2), string(b))
+}
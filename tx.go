@@ -0,0 +1,256 @@
+package orderedmap
+
+// Tx is a handle of changes in OrderedMap.Tx.
+//
+// Set, Delete and MoveTo through it are staged, and made to the map on commit.
+// Get, Contains, Len and Keys see the staged changes.
+type Tx[K comparable, V any] struct {
+	m      *OrderedMap[K, V]
+	parent *Tx[K, V] // of the outer Tx
+
+	ops []txOp[K, V]
+	// the last Set or Delete of each key, by the key of m
+	staged map[K]txEntry[V]
+}
+
+type txOp[K comparable, V any] struct {
+	kind  EventKind // EventInsert for Set, EventDelete or EventMove
+	key   K
+	value V
+	index int
+}
+
+type txEntry[V any] struct {
+	value   V
+	present bool
+}
+
+// Tx calls fn, and makes the changes staged in fn to m if it returns nil.
+// Otherwise they are dropped and the error is returned.
+// If fn panics, they are dropped and the panic goes on.
+//
+// m itself is not changed while fn runs, unless fn changes it directly (not through tx);
+// such changes are restored, values and order, unless fn returns nil.
+// Observers registered by OnChange are notified of the changes on commit.
+// Tx can be nested; an inner Tx stages its changes into the outer one on commit.
+func (m *OrderedMap[K, V]) Tx(fn func(tx *Tx[K, V]) error) (err error) {
+	tx := &Tx[K, V]{m: m, parent: m.openTx}
+	m.openTx = tx
+
+	outer := !m.intx
+	start := len(m.txlog)
+	m.intx = true
+
+	committed := false
+	defer func() {
+		m.openTx = tx.parent
+		if !committed {
+			m.rollback(start)
+		}
+		if !outer {
+			return
+		}
+
+		log := m.txlog
+		m.txlog = nil
+		m.intx = false
+		if committed {
+			m.beginStep()
+			for _, c := range log {
+				m.changed(c)
+			}
+			m.endStep()
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if p := tx.parent; p != nil {
+		p.ops = append(p.ops, tx.ops...)
+		for k, e := range tx.staged {
+			p.stage(k, e)
+		}
+	} else {
+		for _, op := range tx.ops {
+			op.apply(m)
+		}
+	}
+	committed = true
+	return nil
+}
+
+func (tx *Tx[K, V]) Set(key K, value V) {
+	tx.ops = append(tx.ops, txOp[K, V]{kind: EventInsert, key: key, value: value})
+	tx.stage(tx.m.mapKey(key), txEntry[V]{value: value, present: true})
+}
+
+func (tx *Tx[K, V]) Delete(key K) {
+	tx.ops = append(tx.ops, txOp[K, V]{kind: EventDelete, key: key})
+	tx.stage(tx.m.mapKey(key), txEntry[V]{})
+}
+
+// MoveTo stages moving key to the position i.
+// It returns false if key is not found, i is out of range or the map is sorted.
+func (tx *Tx[K, V]) MoveTo(key K, i int) bool {
+	if tx.m.cmp != nil || i < 0 || tx.Len() <= i || !tx.Contains(key) {
+		return false
+	}
+
+	tx.ops = append(tx.ops, txOp[K, V]{kind: EventMove, key: key, index: i})
+	return true
+}
+
+func (tx *Tx[K, V]) MoveToFront(key K) bool {
+	return tx.MoveTo(key, 0)
+}
+
+func (tx *Tx[K, V]) MoveToBack(key K) bool {
+	return tx.MoveTo(key, tx.Len()-1)
+}
+
+func (tx *Tx[K, V]) Get(key K) (V, bool) {
+	k := tx.m.mapKey(key)
+	for t := tx; t != nil; t = t.parent {
+		if e, found := t.staged[k]; found {
+			return e.value, e.present
+		}
+	}
+	return tx.m.Get(key)
+}
+
+func (tx *Tx[K, V]) Contains(key K) bool {
+	_, found := tx.Get(key)
+	return found
+}
+
+func (tx *Tx[K, V]) Len() int {
+	n := tx.m.Len()
+
+	done := make(map[K]bool)
+	for t := tx; t != nil; t = t.parent {
+		for k, e := range t.staged {
+			if done[k] {
+				continue
+			}
+			done[k] = true
+
+			_, found := tx.m.m[k]
+			switch {
+			case e.present && !found:
+				n++
+			case !e.present && found:
+				n--
+			}
+		}
+	}
+	return n
+}
+
+// Keys returns the keys with the staged changes, in O(n).
+func (tx *Tx[K, V]) Keys() []K {
+	s := tx.m.scratch()
+	tx.replay(s)
+	return s.Keys()
+}
+
+func (tx *Tx[K, V]) stage(key K, e txEntry[V]) {
+	if tx.staged == nil {
+		tx.staged = make(map[K]txEntry[V])
+	}
+	tx.staged[key] = e
+}
+
+// replay makes the staged changes, including the outer ones, to m.
+func (tx *Tx[K, V]) replay(m *OrderedMap[K, V]) {
+	if tx.parent != nil {
+		tx.parent.replay(m)
+	}
+	for _, op := range tx.ops {
+		op.apply(m)
+	}
+}
+
+func (op txOp[K, V]) apply(m *OrderedMap[K, V]) {
+	switch op.kind {
+	case EventInsert:
+		m.Set(op.key, op.value)
+	case EventDelete:
+		m.Delete(op.key)
+	case EventMove:
+		m.MoveTo(op.key, op.index)
+	}
+}
+
+// scratch returns a copy of the entries of m, with its settings but without observers nor history.
+func (m *OrderedMap[K, V]) scratch() *OrderedMap[K, V] {
+	s := m.blank()
+
+	order := make([]*elem[K, V], 0, len(m.m))
+	for e := m.first(); e != nil; e = e.next() {
+		c := &elem[K, V]{key: e.key, v: e.v}
+		s.m[m.mapKey(e.key)] = c
+		order = append(order, c)
+	}
+	s.rebuild(order)
+	return s
+}
+
+// rollback reverts the changes in txlog[start:].
+func (m *OrderedMap[K, V]) rollback(start int) {
+	m.muted = true
+	for i := len(m.txlog) - 1; i >= start; i-- {
+		m.revert(m.txlog[i])
+	}
+	m.muted = false
+
+	for i := start; i < len(m.txlog); i++ {
+		m.txlog[i] = change[K, V]{}
+	}
+	m.txlog = m.txlog[:start]
+}
+
+// revert undoes c, which must be the last change made.
+func (m *OrderedMap[K, V]) revert(c change[K, V]) {
+	m.apply(c.inverse())
+}
+
+// apply makes c again, right after the state c was made from.
+func (m *OrderedMap[K, V]) apply(c change[K, V]) {
+	switch c.Kind {
+	case EventInsert:
+		c.e.v = c.NewValue
+		m.m[m.mapKey(c.e.key)] = c.e
+		m.insertAt(c.NewIndex, c.e)
+
+	case EventDelete:
+		delete(m.m, m.mapKey(c.e.key))
+		m.unlink(c.e)
+
+	case EventUpdate, EventMove:
+		c.e.v = c.NewValue
+		if c.OldIndex != c.NewIndex {
+			m.unlink(c.e)
+			m.insertAt(c.NewIndex, c.e)
+		}
+
+	case EventSort, EventReset, EventClear:
+		m.restore(c.after)
+	}
+}
+
+// inverse returns the change that cancels c.
+func (c change[K, V]) inverse() change[K, V] {
+	inv := c
+	switch c.Kind {
+	case EventInsert:
+		inv.Kind = EventDelete
+	case EventDelete:
+		inv.Kind = EventInsert
+	}
+	inv.OldValue, inv.NewValue = c.NewValue, c.OldValue
+	inv.OldIndex, inv.NewIndex = c.NewIndex, c.OldIndex
+	inv.before, inv.after = c.after, c.before
+	return inv
+}
@@ -0,0 +1,228 @@
+package orderedmap_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestTx(t *testing.T) {
+	t.Run("Commit", func(t *testing.T) {
+		m := orderedmap.New[string, int]()
+		m.Set("a", 1)
+		m.Set("b", 2)
+
+		err := m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+			tx.Set("c", 3)
+			tx.Delete("a")
+			tx.MoveToFront("c")
+			gotwant.Test(t, tx.Keys(), []string{"c", "b"})
+			return nil
+		})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"c", "b"})
+	})
+
+	t.Run("Staged", func(t *testing.T) {
+		m := orderedmap.New[string, int]()
+		m.Set("a", 1)
+		m.Set("b", 2)
+
+		m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+			tx.Set("c", 3)
+			tx.Set("a", 10)
+			tx.Delete("b")
+			gotwant.Test(t, tx.MoveTo("b", 0), false)
+			gotwant.Test(t, tx.MoveToFront("c"), true)
+
+			gotwant.Test(t, tx.Len(), 2)
+			gotwant.Test(t, tx.Keys(), []string{"c", "a"})
+			v, _ := tx.Get("a")
+			gotwant.Test(t, v, 10)
+			gotwant.Test(t, tx.Contains("b"), false)
+
+			gotwant.Test(t, m.Keys(), []string{"a", "b"})
+			gotwant.Test(t, m.GetDefault("a", 0), 1)
+			return nil
+		})
+		gotwant.Test(t, m.Keys(), []string{"c", "a"})
+		gotwant.Test(t, m.GetDefault("a", 0), 10)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		m := orderedmap.New[string, int]()
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+
+		errFailed := errors.New("failed")
+		err := m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+			tx.Set("d", 4)
+			tx.Set("a", 10)
+			tx.Delete("b")
+			tx.MoveTo("d", 0)
+			tx.Set("b", 20)
+			m.Sort(func(a, b string) bool { return a > b })
+			tx.Delete("c")
+			return errFailed
+		})
+		gotwant.TestError(t, err, errFailed)
+		gotwant.Test(t, m.Keys(), []string{"a", "b", "c"})
+		gotwant.Test(t, m.Values(), []int{1, 2, 3})
+		gotwant.Test(t, m.IndexOf("c"), 2)
+
+		// still consistent
+		m.Set("d", 4)
+		m.Delete("a")
+		gotwant.Test(t, m.Keys(), []string{"b", "c", "d"})
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		m := orderedmap.New[string, int]()
+		m.Set("a", 1)
+
+		gotwant.TestPanic(t, func() {
+			m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+				tx.Set("b", 2)
+				tx.Delete("a")
+				gotwant.Test(t, tx.Keys(), []string{"b"})
+				gotwant.Test(t, m.Keys(), []string{"a"}) // staged
+				panic("boom")
+			})
+		}, "boom")
+		gotwant.Test(t, m.Keys(), []string{"a"})
+		gotwant.Test(t, m.GetDefault("a", 0), 1)
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		m := orderedmap.New[string, int]()
+
+		err := m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+			tx.Set("a", 1)
+			err := m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+				tx.Set("b", 2)
+				return errors.New("inner")
+			})
+			gotwant.TestError(t, err, "inner")
+			gotwant.Test(t, tx.Keys(), []string{"a"})
+
+			err = m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+				tx.Set("c", 3)
+				return nil
+			})
+			gotwant.TestError(t, err, nil)
+			return nil
+		})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"a", "c"})
+
+		err = m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+			m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+				tx.Delete("a")
+				return nil
+			})
+			return errors.New("outer")
+		})
+		gotwant.TestError(t, err, "outer")
+		gotwant.Test(t, m.Keys(), []string{"a", "c"})
+	})
+
+	t.Run("Events", func(t *testing.T) {
+		m := orderedmap.New[string, int]()
+
+		var events []orderedmap.EventKind
+		m.OnChange(func(ev orderedmap.Event[string, int]) {
+			events = append(events, ev.Kind)
+		})
+
+		m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+			tx.Set("a", 1)
+			gotwant.Test(t, len(events), 0)
+			return errors.New("failed")
+		})
+		gotwant.Test(t, len(events), 0)
+
+		m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+			tx.Set("a", 1)
+			tx.Set("a", 2)
+			gotwant.Test(t, len(events), 0)
+			return nil
+		})
+		gotwant.Test(t, events, []orderedmap.EventKind{orderedmap.EventInsert, orderedmap.EventUpdate})
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		m := orderedmap.New[string, int]()
+		m.Set("a", 1)
+
+		m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+			return json.Unmarshal([]byte(`{"x":1,"y":}`), m)
+		})
+		gotwant.Test(t, m.Keys(), []string{"a"})
+		gotwant.Test(t, m.GetDefault("a", 0), 1)
+	})
+
+	t.Run("Sorted", func(t *testing.T) {
+		m := orderedmap.NewSorted[string, int](strings.Compare, orderedmap.WithKeyNormalizer(strings.ToLower))
+		m.Set("b", 2)
+		m.Set("d", 4)
+
+		m.Tx(func(tx *orderedmap.Tx[string, int]) error {
+			tx.Set("A", 1)
+			tx.Set("c", 3)
+			tx.Delete("D")
+			tx.Set("B", 20)
+			return errors.New("failed")
+		})
+		gotwant.Test(t, m.Keys(), []string{"b", "d"})
+		gotwant.Test(t, m.Values(), []int{2, 4})
+	})
+}
+
+func TestTxRandom(t *testing.T) {
+	m := orderedmap.New[int, int]()
+	for i := 0; i < 50; i++ {
+		m.Set(i, i)
+	}
+
+	for round := 0; round < 100; round++ {
+		keys, values := m.Keys(), m.Values()
+		fail := round%2 == 0
+
+		m.Tx(func(tx *orderedmap.Tx[int, int]) error {
+			for i := 0; i < 30; i++ {
+				k := rand.Intn(70)
+				switch rand.Intn(5) {
+				case 0:
+					tx.Set(k, rand.Int())
+				case 1:
+					tx.Delete(k)
+				case 2:
+					tx.MoveTo(k, rand.Intn(tx.Len()+1))
+				case 3:
+					m.PreserveOrder(rand.Intn(2) == 0)
+					tx.Set(k, -k)
+				case 4:
+					m.Reverse()
+				}
+			}
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		})
+
+		if fail {
+			gotwant.Test(t, m.Keys(), keys)
+			gotwant.Test(t, m.Values(), values)
+			for i, k := range keys {
+				gotwant.Test(t, m.IndexOf(k), i)
+			}
+		}
+	}
+}
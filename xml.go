@@ -0,0 +1,285 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// xmlStyle is how a map is written in XML.
+type xmlStyle struct {
+	attrs   bool
+	entry   string
+	keyAttr string
+}
+
+// WithXMLAttributes makes MarshalXML write scalar values as attributes instead of child elements,
+// and UnmarshalXML read attributes as entries.
+func WithXMLAttributes(b bool) Option {
+	return func(o *options) {
+		o.xml.attrs = b
+	}
+}
+
+// WithXMLEntry sets the names of the fallback form <entry key="..."> (the default),
+// which is used for keys that are not valid XML names.
+func WithXMLEntry(element, keyAttr string) Option {
+	return func(o *options) {
+		o.xml.entry = element
+		o.xml.keyAttr = keyAttr
+	}
+}
+
+func (s xmlStyle) entryName() string {
+	if s.entry == "" {
+		return "entry"
+	}
+	return s.entry
+}
+
+func (s xmlStyle) keyAttrName() string {
+	if s.keyAttr == "" {
+		return "key"
+	}
+	return s.keyAttr
+}
+
+// element returns the start element of the entry of key.
+func (s xmlStyle) element(key string) xml.StartElement {
+	if isXMLName(key) {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: s.entryName()},
+		Attr: []xml.Attr{{Name: xml.Name{Local: s.keyAttrName()}, Value: key}},
+	}
+}
+
+// MarshalXML writes each entry as an element named by its key, in order.
+// Keys that are not valid XML names are written as <entry key="...">.
+//
+// The root element is <map> unless a name is given by the enclosing struct field.
+func (m *OrderedMap[K, V]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if m == nil {
+		return nil
+	}
+
+	if !isXMLName(start.Name.Local) {
+		start.Name = xml.Name{Local: "map"}
+	}
+
+	type child struct {
+		start xml.StartElement
+		value V
+	}
+	children := make([]child, 0, len(m.m))
+	for el := m.first(); el != nil; el = el.next() {
+		name, err := formatKey(el.key)
+		if err != nil {
+			return err
+		}
+
+		if m.xml.attrs && isXMLName(name) {
+			text, ok, err := scalarText(el.v)
+			if err != nil {
+				return err
+			}
+			if ok {
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: text})
+				continue
+			}
+		}
+
+		children = append(children, child{m.xml.element(name), el.v})
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, c := range children {
+		if err := encodeXMLValue(e, c.start, c.value); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func encodeXMLValue(e *xml.Encoder, start xml.StartElement, v any) error {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Invalid:
+		v = nil
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			v = nil
+		} else if rv.Kind() == reflect.Map {
+			// encoding/xml does not support maps
+			p, err := plainValue(v)
+			if err != nil {
+				return err
+			}
+			v = p
+		}
+	}
+
+	if v == nil {
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		return e.EncodeToken(start.End())
+	}
+	return e.EncodeElement(v, start)
+}
+
+// UnmarshalXML reads the child elements as entries, in order.
+//
+// If V is any, an element with children is decoded into *OrderedMap[string, any],
+// the others into string, and repeated elements into []any.
+// Repeated elements are also appended if V is a slice.
+func (m *OrderedMap[K, V]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	defer m.beginDecode()()
+
+	_, err := m.decodeXML(d, start)
+	return err
+}
+
+// decodeXML decodes the content of start into m, and returns the character data.
+func (m *OrderedMap[K, V]) decodeXML(d *xml.Decoder, start xml.StartElement) (string, error) {
+	if m.xml.attrs {
+		for _, a := range start.Attr {
+			if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+				continue
+			}
+
+			k, err := parseText[K](a.Name.Local)
+			if err != nil {
+				return "", err
+			}
+			var v V
+			if err := decodeXMLText(a.Value, &v); err != nil {
+				return "", err
+			}
+			if err := m.setDecoded(k, v); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	var text bytes.Buffer
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+
+		case xml.StartElement:
+			name := t.Name.Local
+			if name == m.xml.entryName() {
+				for i, a := range t.Attr {
+					if a.Name.Local == m.xml.keyAttrName() {
+						name = a.Value
+						t.Attr = append(t.Attr[:i:i], t.Attr[i+1:]...)
+						break
+					}
+				}
+			}
+
+			k, err := parseText[K](name)
+			if err != nil {
+				return "", err
+			}
+			if err := m.decodeXMLEntry(d, t, k); err != nil {
+				return "", err
+			}
+
+		case xml.EndElement:
+			return text.String(), nil
+		}
+	}
+}
+
+func (m *OrderedMap[K, V]) decodeXMLEntry(d *xml.Decoder, start xml.StartElement, key K) error {
+	existing, found := m.Get(key)
+
+	var v V
+	if p, ok := any(&v).(*any); ok {
+		sub := New[string, any]()
+		sub.overwriteSeq = m.overwriteSeq
+		sub.xml = m.xml
+		text, err := sub.decodeXML(d, start)
+		if err != nil {
+			return err
+		}
+
+		var x any = sub
+		if sub.Len() == 0 {
+			x = text
+		}
+
+		if found {
+			// repeated
+			list, ok := any(existing).([]any)
+			if !ok {
+				list = []any{existing}
+			}
+			x = append(list, x)
+		}
+		*p = x
+		m.Set(key, v)
+		return nil
+	}
+
+	if found && reflect.TypeOf(v) != nil && reflect.TypeOf(v).Kind() == reflect.Slice {
+		// repeated
+		v = existing
+		if err := d.DecodeElement(&v, &start); err != nil {
+			return err
+		}
+		m.Set(key, v)
+		return nil
+	}
+
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	return m.setDecoded(key, v)
+}
+
+// decodeXMLText decodes s as the text content of an element.
+func decodeXMLText[V any](s string, v *V) error {
+	if p, ok := any(v).(*any); ok {
+		*p = s
+		return nil
+	}
+
+	var b bytes.Buffer
+	b.WriteString("<v>")
+	if err := xml.EscapeText(&b, []byte(s)); err != nil {
+		return err
+	}
+	b.WriteString("</v>")
+	return xml.Unmarshal(b.Bytes(), v)
+}
+
+func isXMLName(s string) bool {
+	if s == "" || strings.HasPrefix(strings.ToLower(s), "xml") {
+		return false
+	}
+
+	for i, r := range s {
+		if unicode.IsLetter(r) || r == '_' {
+			continue
+		}
+		if i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.') {
+			continue
+		}
+		return false
+	}
+	return true
+}
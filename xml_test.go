@@ -0,0 +1,93 @@
+package orderedmap_test
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+)
+
+func TestMarshalXML(t *testing.T) {
+	sub := orderedmap.New[string, any]()
+	sub.Set("y", 2)
+	sub.Set("x", 1)
+
+	m := orderedmap.New[string, any]()
+	m.Set("zeta", "z&z")
+	m.Set("alpha", 1.5)
+	m.Set("point", sub)
+	m.Set("with space", true)
+	m.Set("tags", []any{"a", "b"})
+
+	b, err := xml.Marshal(m)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, string(b), `<map><zeta>z&amp;z</zeta><alpha>1.5</alpha><point><y>2</y><x>1</x></point><entry key="with space">true</entry><tags>a</tags><tags>b</tags></map>`)
+
+	t.Run("Attributes", func(t *testing.T) {
+		m := orderedmap.New[string, any](orderedmap.WithXMLAttributes(true), orderedmap.WithXMLEntry("item", "name"))
+		m.Set("id", 10)
+		m.Set("point", sub)
+		m.Set("with space", "s")
+		m.Set("name", "n")
+
+		b, err := xml.Marshal(m)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, string(b), `<map id="10" name="n"><point><y>2</y><x>1</x></point><item name="with space">s</item></map>`)
+	})
+
+	t.Run("Field", func(t *testing.T) {
+		type doc struct {
+			XMLName xml.Name `xml:"doc"`
+			Params  *orderedmap.OrderedMap[string, int]
+		}
+		params := orderedmap.New[string, int]()
+		params.Set("b", 2)
+		params.Set("a", 1)
+
+		b, err := xml.Marshal(doc{Params: params})
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, string(b), `<doc><Params><b>2</b><a>1</a></Params></doc>`)
+
+		var d doc
+		err = xml.Unmarshal(b, &d)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, d.Params.Keys(), []string{"b", "a"})
+		gotwant.Test(t, d.Params.Values(), []int{2, 1})
+	})
+}
+
+func TestUnmarshalXML(t *testing.T) {
+	m := orderedmap.New[string, any]()
+	err := xml.Unmarshal([]byte(`<map>
+	<zeta>z</zeta>
+	<point><y>2</y><x>1</x></point>
+	<entry key="with space">s</entry>
+	<tags>a</tags>
+	<tags>b</tags>
+</map>`), m)
+	gotwant.TestError(t, err, nil)
+	gotwant.Test(t, m.Keys(), []string{"zeta", "point", "with space", "tags"})
+	gotwant.Test(t, m.GetDefault("point", nil).(*orderedmap.OrderedMap[string, any]).Keys(), []string{"y", "x"})
+	gotwant.Test(t, m.GetDefault("with space", nil), "s")
+	gotwant.Test(t, m.GetDefault("tags", nil), []any{"a", "b"})
+
+	t.Run("Attributes", func(t *testing.T) {
+		m := orderedmap.New[string, int](orderedmap.WithXMLAttributes(true))
+		err := xml.Unmarshal([]byte(`<map z="1" y="2"><x>3</x></map>`), m)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"z", "y", "x"})
+		gotwant.Test(t, m.Values(), []int{1, 2, 3})
+
+		err = xml.Unmarshal([]byte(`<map z="a"></map>`), m)
+		gotwant.TestError(t, err, "invalid syntax")
+	})
+
+	t.Run("Slice", func(t *testing.T) {
+		m := orderedmap.New[string, []string]()
+		err := xml.Unmarshal([]byte(`<map><b>1</b><a>2</a><b>3</b></map>`), m)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, m.Keys(), []string{"b", "a"})
+		gotwant.Test(t, m.GetDefault("b", nil), []string{"1", "3"})
+	})
+}
@@ -0,0 +1,79 @@
+package orderedmap_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shu-go/gotwant"
+	"github.com/shu-go/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+const yamlDoc = `# the name
+name: app # line comment
+server:
+  # the port
+  port: 8080
+  host: "localhost"
+tags: [a, b] # flow
+`
+
+func encodeYAML(t *testing.T, v any) string {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	err := enc.Encode(v)
+	gotwant.TestError(t, err, nil)
+	return buf.String()
+}
+
+func TestYAMLComments(t *testing.T) {
+	t.Run("Untouched", func(t *testing.T) {
+		m := orderedmap.New[string, any](orderedmap.WithComments(true))
+		err := yaml.Unmarshal([]byte(yamlDoc), m)
+		gotwant.TestError(t, err, nil)
+		gotwant.Test(t, encodeYAML(t, m), yamlDoc)
+	})
+
+	t.Run("Edited", func(t *testing.T) {
+		m := orderedmap.New[string, any](orderedmap.WithComments(true))
+		err := yaml.Unmarshal([]byte(yamlDoc), m)
+		gotwant.TestError(t, err, nil)
+
+		m.Set("name", "app2")
+		m.Set("tags", []string{"c"})
+		m.Set("added", true)
+		gotwant.Test(t, encodeYAML(t, m), `# the name
+name: app2 # line comment
+server:
+  # the port
+  port: 8080
+  host: "localhost"
+tags: [c] # flow
+added: true
+`)
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		m := orderedmap.New[string, *orderedmap.OrderedMap[string, any]](orderedmap.WithComments(true))
+		err := yaml.Unmarshal([]byte(`server:
+  # the port
+  port: 8080
+  host: "localhost" # quoted
+`), m)
+		gotwant.TestError(t, err, nil)
+
+		server := m.GetDefault("server", nil)
+		gotwant.Test(t, server.Keys(), []string{"port", "host"})
+		server.Set("host", "example.com")
+		server.Set("port", 80)
+
+		gotwant.Test(t, encodeYAML(t, m), `server:
+  # the port
+  port: 80
+  host: "example.com" # quoted
+`)
+	})
+}